@@ -1,98 +1,68 @@
 package address
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"log"
-	"sync"
-
-	"golang.org/x/crypto/sha3"
 )
 
-// makeAddress returns the address based on the public spend key and the public view key
-func makeAddress(pubSpend, pubView PublicKey) []byte {
-	// A Monero address 'mAddr' looks as follows:
-	// c = netBytePrefix(0x12) | publicSpendKey | publicViewKey
-	// mAddr = base58encode(c | checksum(c)[:4])
-	const netBytePrefix = byte(18)
-	buf := make([]byte, 0, 69)
-	buf = append(buf, netBytePrefix)
-	buf = append(buf, pubSpend...)
-	buf = append(buf, pubView...)
-	h := sha3.NewLegacyKeccak256()
-	if _, err := h.Write(buf); err != nil {
-		panic(err)
-	}
-	hash := h.Sum(nil)
-	buf = append(buf, hash[:4]...)
-	return base58encode(buf)
+// makeAddress returns the address based on the network byte,
+// the public spend key and the public view key
+func makeAddress(netBytePrefix byte, pubSpend, pubView PublicKey) []byte {
+	c := newCandidate()
+	copy(c.spendPub[:], pubSpend)
+	copy(c.viewPub[:], pubView)
+	c.encodeAddress(netBytePrefix)
+	return c.address[:]
 }
 
-func New() (*KeyPair, *KeyPair, []byte, error) {
+// checksum returns the 4-byte Keccak-256 checksum of data
+func checksum(data []byte) []byte {
+	c := newCandidate()
+	c.hash(data)
+	return c.digest[:checksumSize]
+}
+
+func New(net Network) (*KeyPair, *KeyPair, []byte, error) {
 	spendKeyPair, err := newSpendKeyPair()
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to create new spend key pair: %s", err.Error())
 	}
 	viewKeyPair := makeViewKeyPair(spendKeyPair.PrivateKey())
-	address := makeAddress(spendKeyPair.PublicKey(), viewKeyPair.PublicKey())
+	address := makeAddress(net.StandardPrefix, spendKeyPair.PublicKey(), viewKeyPair.PublicKey())
 	return spendKeyPair, viewKeyPair, address, nil
 }
 
-func NewWithPrefix(prefix []byte, numWorkers int) (*KeyPair, *KeyPair, []byte, error) {
-	type result struct {
-		spendKeyPair, viewKeyPair *KeyPair
-		address                   []byte
-	}
+// Result is a wallet found by a vanity search. The keys of a
+// split-key search only make up a wallet when combined with the
+// SplitKey, see SearchOptions.SplitSpendKey.
+type Result struct {
+	SpendKeyPair, ViewKeyPair *KeyPair
+	Address                   []byte
+	// Pattern is the pattern the address matches
+	Pattern []byte
+	// Match is the part of the address which matches the
+	// pattern, e.g. the prefix in the casing that was found
+	Match []byte
+	// Attempts is the number of candidates the workers
+	// checked until the wallet was found
+	Attempts uint64
 
-	spawn := func(wid int, ch chan<- *result, mu *sync.Mutex, done chan struct{}) error {
-		spendKeyPair, err := newSpendKeyPair()
-		if err != nil {
-			return fmt.Errorf("failed to create new spend key pair in worker %d: %q", wid, err)
-		}
-		go func() {
-			nextSpendKeyPair := nextSpendKeyPairMaker(spendKeyPair)
-			var viewKeyPair *KeyPair
-			address := make([]byte, 2)
-			for !bytes.HasPrefix(address[2:], prefix) {
-				select {
-				case <-done:
-					break
-				default:
-					nextSpendKeyPair()
-					viewKeyPair = makeViewKeyPair(spendKeyPair.PrivateKey())
-					address = makeAddress(spendKeyPair.PublicKey(), viewKeyPair.PublicKey())
-				}
-			}
-			// Try to send our result
-			mu.Lock()
-			defer mu.Unlock()
-			select {
-			case <-done:
-				// Closed
-			default:
-				close(done)
-				ch <- &result{
-					viewKeyPair:  viewKeyPair,
-					spendKeyPair: spendKeyPair,
-					address:      address,
-				}
-			}
-		}()
-		return nil
-	}
+	// worker is the worker which found the wallet and position
+	// its position right after it, see SearchState.Advance
+	worker   int
+	position WorkerState
+}
 
-	ch := make(chan *result)
-	var rch chan<- *result = ch
-	var mu sync.Mutex
-	done := make(chan struct{})
-	for i := 0; i < numWorkers; i++ {
-		if err := spawn(i, rch, &mu, done); err != nil {
-			log.Printf("%q, retrying", err)
-			i-- // Retry
-		}
-	}
-	res := <-ch
-	close(ch)
+func NewWithPrefix(net Network, prefix []byte, numWorkers int) (*Result, error) {
+	return NewWithPrefixContext(context.Background(), net, prefix, numWorkers)
+}
 
-	return res.spendKeyPair, res.viewKeyPair, res.address, nil
+// NewWithPrefixContext is like NewWithPrefix but gives up once ctx is done,
+// in which case it returns ctx.Err(). All workers have exited when it returns.
+func NewWithPrefixContext(ctx context.Context, net Network, prefix []byte, numWorkers int) (*Result, error) {
+	return NewWithOptions(ctx, SearchOptions{
+		Network: net,
+		Prefix:  prefix,
+		Workers: numWorkers,
+	})
 }
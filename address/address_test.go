@@ -2,10 +2,12 @@ package address
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"runtime"
 	"testing"
+	"time"
 )
 
 type fixture struct {
@@ -58,38 +60,235 @@ func TestPrivateSpend2ViewPair(t *testing.T) {
 	}, t)
 }
 
+func TestKeyWalker(t *testing.T) {
+	// l-1, the next private key wraps around to zero
+	privs := []string{"ecd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010"}
+	for _, fx := range fixtures {
+		privs = append(privs, fx.privSpendHex)
+	}
+	for _, batchSize := range []int{1, 7} {
+		for _, priv := range privs {
+			w := newKeyWalker(h2b(priv), batchSize)
+			prev := h2b(priv)
+			for i := 0; i < 3; i++ {
+				w.next()
+				for j := range w.pubs {
+					var priv [32]byte
+					w.privateKeyTo(&priv, j)
+					p := &KeyPair{priv[:], w.pubs[j][:]}
+					if !isReduced(p.PrivateKey()) {
+						t.Fatalf("got unreduced private key %s", b2h(p.PrivateKey()))
+					}
+					if expected := reduce(addOne(prev)); !bytes.Equal(p.PrivateKey(), expected) {
+						t.Fatalf("got private key %s, expected %s", b2h(p.PrivateKey()), b2h(expected))
+					}
+					if got := private2Public(p.PrivateKey()); !bytes.Equal(got, p.PublicKey()) {
+						t.Fatalf("got public key %s, expected %s", b2h(p.PublicKey()), b2h(got))
+					}
+					prev = p.PrivateKey()
+				}
+			}
+		}
+	}
+}
+
+// addOne returns the little-endian number n+1
+func addOne(n []byte) []byte {
+	res := append([]byte(nil), n...)
+	for i := range res {
+		if res[i]++; res[i] != 0 {
+			break
+		}
+	}
+	return res
+}
+
+// BenchmarkKeyWalker measures the time per key. A batch size
+// of 1 pays for a field inversion with every key.
+func BenchmarkKeyWalker(b *testing.B) {
+	for _, batchSize := range []int{1, 16, searchBatchSize} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			p, err := newSpendKeyPair()
+			if err != nil {
+				b.Fatal(err)
+			}
+			w := newKeyWalker(p.PrivateKey(), batchSize)
+			b.ResetTimer()
+			for i := 0; i < b.N; i += batchSize {
+				w.next()
+			}
+		})
+	}
+}
+
+func BenchmarkPrivate2Public(b *testing.B) {
+	p, err := newSpendKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		private2Public(p.PrivateKey())
+	}
+}
+
 func TestMakeAddress(t *testing.T) {
 	foreachFixture(func(fx fixture) error {
-		if got := makeAddress(h2b(fx.pubSpendHex), h2b(fx.pubViewHex)); string(got) != fx.address {
+		if got := makeAddress(Mainnet.StandardPrefix, h2b(fx.pubSpendHex), h2b(fx.pubViewHex)); string(got) != fx.address {
 			return fmt.Errorf("got incorrect address: %s", got)
 		}
 		return nil
 	}, t)
 }
 
+func TestFromPrivateSpendKey(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		spendKeyPair, viewKeyPair, err := FromPrivateSpendKey(h2b(fx.privSpendHex))
+		if err != nil {
+			return err
+		}
+		if got := b2h(spendKeyPair.PublicKey()); got != fx.pubSpendHex {
+			return fmt.Errorf("got incorrect public spend key: %s", got)
+		}
+		if got := b2h(viewKeyPair.PrivateKey()); got != fx.privViewHex {
+			return fmt.Errorf("got incorrect private view key: %s", got)
+		}
+		if got := b2h(viewKeyPair.PublicKey()); got != fx.pubViewHex {
+			return fmt.Errorf("got incorrect public view key: %s", got)
+		}
+		return nil
+	}, t)
+
+	// l, the order of the base point, is not reduced
+	l := h2b("edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+	for _, priv := range [][]byte{nil, l, make([]byte, 32), h2b(fixtures[0].privSpendHex)[1:]} {
+		if _, _, err := FromPrivateSpendKey(priv); err == nil {
+			t.Fatalf("expected error for invalid private spend key %x", priv)
+		}
+	}
+}
+
 func TestNewAddressWithoutPrefix(t *testing.T) {
-	if err := testAddress(nil); err != nil {
+	if err := testAddress(Mainnet, nil); err != nil {
 		t.Fatal(err)
 	}
 }
 
 func TestNewAddressWithPrefix(t *testing.T) {
 	prefix := []byte("a")
-	if err := testAddress(prefix); err != nil {
+	if err := testAddress(Mainnet, prefix); err != nil {
 		t.Fatal(err)
 	}
 }
 
-func testAddress(prefix []byte) error {
+func TestNewAddressWithPrefixContext(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// No address will be found with this prefix in time
+	prefix := bytes.Repeat([]byte("a"), 20)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := NewWithPrefixContext(ctx, Mainnet, prefix, 4); err != context.DeadlineExceeded {
+		t.Fatalf("expected %q, got %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewWithPrefixContext(ctx, Mainnet, prefix, 4); err != context.Canceled {
+		t.Fatalf("expected %q, got %v", context.Canceled, err)
+	}
+
+	if _, err := NewWithPrefixContext(context.Background(), Mainnet, []byte("a"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exited goroutines may take a moment to disappear from the count
+	for i := 0; runtime.NumGoroutine() > before; i++ {
+		if i == 100 {
+			t.Fatalf("leaked %d goroutines", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewWithOptionsIgnoreCase(t *testing.T) {
+	prefix := []byte("aB")
+	res, err := NewWithOptions(context.Background(), SearchOptions{
+		Network:    Mainnet,
+		Prefix:     prefix,
+		IgnoreCase: true,
+		Workers:    2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.EqualFold(res.Match, prefix) {
+		t.Fatalf("got match %q, expected %q in any casing", res.Match, prefix)
+	}
+	if !bytes.HasPrefix(res.Address[2:], res.Match) {
+		t.Fatalf("address %s does not have match %q as prefix", res.Address, res.Match)
+	}
+
+	m := &prefixMatcher{offset: 2, prefix: []byte("cafe"), ignoreCase: true}
+	for _, block := range []string{"44cafe", "44CAFE", "44CaFexxxxx"} {
+		if !m.MatchBlock([]byte(block)) {
+			t.Fatalf("expected block %q to be accepted", block)
+		}
+	}
+	for _, block := range []string{"44cafx", "4cafe"} {
+		if m.MatchBlock([]byte(block)) {
+			t.Fatalf("expected block %q to be rejected", block)
+		}
+	}
+}
+
+func TestNetworks(t *testing.T) {
+	leading := map[string]string{
+		"mainnet":  "4",
+		"testnet":  "9A",
+		"stagenet": "5",
+	}
+	for _, net := range Networks() {
+		if got := net.leadingChars(); got != 2 {
+			t.Fatalf("%s: got unexpected number of leading characters: %d", net, got)
+		}
+		if err := testAddress(net, []byte("a")); err != nil {
+			t.Fatalf("%s: %s", net, err.Error())
+		}
+		_, _, address, err := New(net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.ContainsRune([]byte(leading[net.Name]), rune(address[0])) {
+			t.Fatalf("%s: got address with unexpected leading character: %s", net, address)
+		}
+	}
+}
+
+func TestNetworkByName(t *testing.T) {
+	if net, err := NetworkByName("Stagenet"); err != nil || net != Stagenet {
+		t.Fatalf("got incorrect network: %s, %v", net, err)
+	}
+	if _, err := NetworkByName("devnet"); err == nil {
+		t.Fatal("expected error for unknown network")
+	}
+}
+
+func testAddress(net Network, prefix []byte) error {
 	var (
 		spendKeyPair, viewKeyPair *KeyPair
 		address                   []byte
 		err                       error
 	)
 	if prefix == nil {
-		spendKeyPair, viewKeyPair, address, err = New()
+		spendKeyPair, viewKeyPair, address, err = New(net)
 	} else {
-		spendKeyPair, viewKeyPair, address, err = NewWithPrefix(prefix, runtime.GOMAXPROCS(-1))
+		var res *Result
+		if res, err = NewWithPrefix(net, prefix, runtime.GOMAXPROCS(-1)); err == nil {
+			spendKeyPair, viewKeyPair, address = res.SpendKeyPair, res.ViewKeyPair, res.Address
+			if res.Attempts == 0 {
+				return fmt.Errorf("got no attempts")
+			}
+		}
 	}
 	if err != nil {
 		return fmt.Errorf("failed to create new address: %s", err.Error())
@@ -0,0 +1,77 @@
+package address
+
+import (
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// encodedAddressSize is the length of an encoded standard address
+const encodedAddressSize = 95
+
+// candidate holds the keys and the address of a wallet candidate.
+// Its buffers are reused for every candidate a worker checks,
+// so deriving keys and encoding addresses does not allocate.
+type candidate struct {
+	spendPriv, spendPub [keySize]byte
+	viewPriv, viewPub   [keySize]byte
+	data                [1 + 2*keySize + checksumSize]byte
+	address             [encodedAddressSize]byte
+	digest              [32]byte
+	keccak              hash.Hash
+	// sponge is keccak if its digest can be read, or nil
+	sponge io.Reader
+}
+
+func newCandidate() *candidate {
+	keccak := sha3.NewLegacyKeccak256()
+	// Reading the digest, unlike Sum, does not copy the hash state
+	sponge, _ := keccak.(io.Reader)
+	return &candidate{keccak: keccak, sponge: sponge}
+}
+
+// hash sets digest to the Keccak-256 hash of data
+func (c *candidate) hash(data []byte) {
+	c.keccak.Reset()
+	if _, err := c.keccak.Write(data); err != nil {
+		panic(err)
+	}
+	if c.sponge == nil {
+		c.keccak.Sum(c.digest[:0])
+		return
+	}
+	if _, err := c.sponge.Read(c.digest[:]); err != nil {
+		panic(err)
+	}
+}
+
+// deriveViewKey sets the view key pair based on the private spend key
+func (c *candidate) deriveViewKey() {
+	// Hash private spend key using Keccak-256
+	c.hash(c.spendPriv[:])
+	// Important: Reduce to stay in finite field
+	reduceTo(&c.viewPriv, c.digest[:])
+	// Turn private into public key
+	private2PublicTo(&c.viewPub, &c.viewPriv)
+}
+
+// encodeAddress sets address to the standard address of the public keys
+func (c *candidate) encodeAddress(netBytePrefix byte) {
+	// A Monero address 'mAddr' looks as follows:
+	// c = netBytePrefix(0x12 on mainnet) | publicSpendKey | publicViewKey
+	// mAddr = base58encode(c | checksum(c)[:4])
+	c.data[0] = netBytePrefix
+	copy(c.data[1:], c.spendPub[:])
+	copy(c.data[1+keySize:], c.viewPub[:])
+	c.hash(c.data[:1+2*keySize])
+	copy(c.data[1+2*keySize:], c.digest[:checksumSize])
+	base58encodeTo(c.address[:], c.data[:])
+}
+
+// keyPairs returns copies of the spend and view key pairs
+func (c *candidate) keyPairs() (*KeyPair, *KeyPair) {
+	spendPriv, spendPub := c.spendPriv, c.spendPub
+	viewPriv, viewPub := c.viewPriv, c.viewPub
+	return &KeyPair{spendPriv[:], spendPub[:]}, &KeyPair{viewPriv[:], viewPub[:]}
+}
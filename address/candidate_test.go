@@ -0,0 +1,111 @@
+package address
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestCandidate returns a candidate with
+// the keys and the address of the first fixture
+func newTestCandidate() *candidate {
+	fx := fixtures[0]
+	c := newCandidate()
+	copy(c.spendPriv[:], h2b(fx.privSpendHex))
+	copy(c.spendPub[:], h2b(fx.pubSpendHex))
+	c.deriveViewKey()
+	c.encodeAddress(Mainnet.StandardPrefix)
+	return c
+}
+
+func TestCandidate(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		// Without a sponge, the digest is computed with Sum
+		for _, readable := range []bool{true, false} {
+			c := newCandidate()
+			if c.sponge == nil {
+				return fmt.Errorf("expected the digest of Keccak-256 to be readable")
+			}
+			if !readable {
+				c.sponge = nil
+			}
+			copy(c.spendPriv[:], h2b(fx.privSpendHex))
+			copy(c.spendPub[:], h2b(fx.pubSpendHex))
+			c.deriveViewKey()
+			if got := b2h(c.viewPriv[:]); got != fx.privViewHex {
+				return fmt.Errorf("got incorrect private view key: %s", got)
+			}
+			if got := b2h(c.viewPub[:]); got != fx.pubViewHex {
+				return fmt.Errorf("got incorrect public view key: %s", got)
+			}
+			c.encodeAddress(Mainnet.StandardPrefix)
+			if got := string(c.address[:]); got != fx.address {
+				return fmt.Errorf("got incorrect address: %s", got)
+			}
+		}
+		return nil
+	}, t)
+}
+
+var (
+	// stages are the stages of checking a prefix search candidate
+	stages = []struct {
+		name string
+		// prepare returns the stage to run repeatedly
+		prepare func() func()
+	}{
+		{"walk", func() func() {
+			w := newKeyWalker(h2b(fixtures[0].privSpendHex), searchBatchSize)
+			return w.next
+		}},
+		{"private-key", func() func() {
+			w := newKeyWalker(h2b(fixtures[0].privSpendHex), searchBatchSize)
+			w.next()
+			c := newCandidate()
+			return func() { w.privateKeyTo(&c.spendPriv, searchBatchSize-1) }
+		}},
+		{"first-block", func() func() {
+			c := newTestCandidate()
+			var block [fullEncodedBlockSize]byte
+			return func() { encodeFirstBlock(&block, Mainnet.StandardPrefix, c.spendPub[:]) }
+		}},
+		{"reduce", func() func() {
+			c := newTestCandidate()
+			return func() { reduceTo(&c.viewPriv, c.digest[:]) }
+		}},
+		{"private2public", func() func() {
+			c := newTestCandidate()
+			return func() { private2PublicTo(&c.viewPub, &c.viewPriv) }
+		}},
+		{"view-key", func() func() {
+			c := newTestCandidate()
+			return c.deriveViewKey
+		}},
+		{"address", func() func() {
+			c := newTestCandidate()
+			return func() { c.encodeAddress(Mainnet.StandardPrefix) }
+		}},
+	}
+)
+
+func TestCandidateAllocs(t *testing.T) {
+	for _, s := range stages {
+		if allocs := testing.AllocsPerRun(100, s.prepare()); allocs != 0 {
+			t.Errorf("%s: got %.1f allocations per run, expected none", s.name, allocs)
+		}
+	}
+}
+
+// BenchmarkCandidate measures each stage of checking a candidate.
+// The walk stage derives a whole batch of keys per run.
+func BenchmarkCandidate(b *testing.B) {
+	for _, s := range stages {
+		b.Run(s.name, func(b *testing.B) {
+			run := s.prepare()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+// digitSet is a set of Base58 digits
+type digitSet [58]bool
+
+// size returns the number of digits in the set
+func (s *digitSet) size() int64 {
+	var n int64
+	for _, ok := range s {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// charDigits returns the set of digits which encode c,
+// in any casing if ignoreCase is set
+func charDigits(c byte, ignoreCase bool) *digitSet {
+	var s digitSet
+	for d, a := range alphabet {
+		if a == c || ignoreCase && a|0x20 == c|0x20 && 'a' <= c|0x20 && c|0x20 <= 'z' {
+			s[d] = true
+		}
+	}
+	return &s
+}
+
+// addressBlock is a block of a standard address
+type addressBlock struct {
+	// lo and hi limit the range [lo, hi) of its values
+	lo, hi *big.Int
+	// size is the number of characters it is encoded into
+	size int
+}
+
+// addressBlocks returns the blocks of a standard address of the network.
+// The first block starts with the network byte, all other bytes are
+// uniformly distributed.
+func addressBlocks(net Network) []addressBlock {
+	size := 1 + 2*keySize + checksumSize
+	var blocks []addressBlock
+	for i := 0; i < size; i += fullBlockSize {
+		n := fullBlockSize
+		if size-i < n {
+			n = size - i
+		}
+		b := addressBlock{
+			lo:   new(big.Int),
+			hi:   new(big.Int).Lsh(big.NewInt(1), 8*uint(n)),
+			size: encodedBlockSizes[n],
+		}
+		if i == 0 {
+			// The network byte is the most significant byte
+			b.lo.Lsh(big.NewInt(int64(net.StandardPrefix)), 8*uint(n-1))
+			b.hi.Lsh(big.NewInt(int64(net.StandardPrefix)+1), 8*uint(n-1))
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// countBelow returns the number of values in [0, x) whose encoding into
+// len(sets) digits only consists of digits of the sets. A nil set
+// allows all digits.
+func countBelow(x *big.Int, sets []*digitSet) *big.Int {
+	// Digits of x, most significant first
+	digits := make([]int, len(sets))
+	rem, digit := new(big.Int).Set(x), new(big.Int)
+	base := big.NewInt(int64(len(alphabet)))
+	for i := len(digits) - 1; i >= 0; i-- {
+		rem.QuoRem(rem, base, digit)
+		digits[i] = int(digit.Int64())
+	}
+
+	// sizes[i] is the number of digit sequences allowed after position i
+	sizes := make([]*big.Int, len(sets)+1)
+	sizes[len(sets)] = big.NewInt(1)
+	for i := len(sets) - 1; i >= 0; i-- {
+		n := int64(len(alphabet))
+		if sets[i] != nil {
+			n = sets[i].size()
+		}
+		sizes[i] = new(big.Int).Mul(sizes[i+1], big.NewInt(n))
+	}
+	if rem.Sign() > 0 {
+		// x exceeds all values of len(sets) digits
+		return sizes[0]
+	}
+
+	// Count the values which equal x up to position i
+	// and are smaller than x at position i
+	count, n := new(big.Int), new(big.Int)
+	for i, s := range sets {
+		var less int64
+		for d := 0; d < digits[i]; d++ {
+			if s == nil || s[d] {
+				less++
+			}
+		}
+		count.Add(count, n.Mul(big.NewInt(less), sizes[i+1]))
+		if s != nil && !s[digits[i]] {
+			break
+		}
+	}
+	return count
+}
+
+// probability returns the probability that the block only
+// consists of digits of the sets
+func (b *addressBlock) probability(sets []*digitSet) float64 {
+	count := new(big.Int).Sub(countBelow(b.hi, sets), countBelow(b.lo, sets))
+	total := new(big.Int).Sub(b.hi, b.lo)
+	p, _ := new(big.Rat).SetFrac(count, total).Float64()
+	return p
+}
+
+// positionProbability returns the probability that a random standard
+// address of the network has the i-th of the sets at position pos+i
+// for all i. A nil set allows all digits.
+func positionProbability(net Network, pos int, sets []*digitSet) float64 {
+	if pos < 0 || pos+len(sets) > encodedAddressSize {
+		return 0
+	}
+	p := 1.0
+	var start int
+	for _, b := range addressBlocks(net) {
+		// The sets of the characters of this block
+		blockSets := make([]*digitSet, b.size)
+		var constrained bool
+		for i := range blockSets {
+			if j := start + i - pos; j >= 0 && j < len(sets) {
+				blockSets[i] = sets[j]
+				constrained = constrained || sets[j] != nil
+			}
+		}
+		if constrained {
+			p *= b.probability(blockSets)
+		}
+		start += b.size
+	}
+	return p
+}
+
+// wordProbability returns the probability that a random
+// standard address of the network has word at position pos
+func wordProbability(net Network, word []byte, pos int, ignoreCase bool) float64 {
+	sets := make([]*digitSet, len(word))
+	for i, c := range word {
+		sets[i] = charDigits(c, ignoreCase)
+	}
+	return positionProbability(net, pos, sets)
+}
+
+// wordAutomaton tracks the partial matches of a word in a sequence of
+// digits. A state is the set of lengths j such that the last j digits
+// match the first j characters of the word. The word was found once
+// its full length matches, which is the final state.
+type wordAutomaton struct {
+	sets []*digitSet
+	// index maps a state to its number, states maps it back
+	index  map[string]int
+	states []string
+	// next holds the successors of each state for each digit
+	next [][]int
+	// found is the number of the final state
+	found int
+}
+
+func newWordAutomaton(sets []*digitSet) *wordAutomaton {
+	a := &wordAutomaton{sets: sets, index: make(map[string]int)}
+	a.state(make([]byte, len(sets)))
+	final := make([]byte, len(sets)+1)
+	final[len(sets)] = 1
+	a.found = a.state(final)
+	return a
+}
+
+// state returns the number of state s, which holds a 1 at
+// index j if the last j digits match the word
+func (a *wordAutomaton) state(s []byte) int {
+	if i, ok := a.index[string(s)]; ok {
+		return i
+	}
+	i := len(a.states)
+	a.index[string(s)] = i
+	a.states = append(a.states, string(s))
+	a.next = append(a.next, nil)
+	return i
+}
+
+// step returns the successor of state i after digit d
+func (a *wordAutomaton) step(i, d int) int {
+	if i == a.found {
+		return i
+	}
+	if a.next[i] == nil {
+		s, next := a.states[i], make([]int, len(alphabet))
+		for e := range next {
+			t := make([]byte, len(a.sets))
+			for j := range a.sets {
+				// The empty prefix always matches
+				if (j == 0 || s[j] == 1) && a.sets[j][e] {
+					if j+1 == len(a.sets) {
+						t = nil
+						break
+					}
+					t[j+1] = 1
+				}
+			}
+			if t == nil {
+				next[e] = a.found
+				continue
+			}
+			next[e] = a.state(t)
+		}
+		a.next[i] = next
+	}
+	return a.next[i][d]
+}
+
+// countBelow adds the number of values in [0, x) of a block of size
+// digits, which lead from state i to each state, to counts with sign.
+// The values are split up by the position of their first digit below
+// the digit of x, after which all digits are free.
+func (a *wordAutomaton) countBelow(counts map[int]float64, sign float64, x *big.Int, size, i int) {
+	digits := make([]int, size)
+	rem, digit := new(big.Int).Set(x), new(big.Int)
+	base := big.NewInt(int64(len(alphabet)))
+	for j := size - 1; j >= 0; j-- {
+		rem.QuoRem(rem, base, digit)
+		digits[j] = int(digit.Int64())
+	}
+	free := make(map[int]float64)
+	if rem.Sign() > 0 {
+		// x exceeds all values of size digits
+		free[i] = 1
+		digits = nil
+	}
+	for j := 0; j < size; j++ {
+		nextFree := make(map[int]float64)
+		for t, n := range free {
+			for d := range alphabet {
+				nextFree[a.step(t, d)] += n
+			}
+		}
+		if digits != nil {
+			for d := 0; d < digits[j]; d++ {
+				nextFree[a.step(i, d)]++
+			}
+			i = a.step(i, digits[j])
+		}
+		free = nextFree
+	}
+	for t, n := range free {
+		counts[t] += sign * n
+	}
+}
+
+// containsProbability returns the probability that a random standard
+// address of the network has the sets at any position. Unlike the sum
+// over all positions, it accounts for overlapping instances of the
+// word and for the digits of a block not being independent.
+func containsProbability(net Network, sets []*digitSet) float64 {
+	if len(sets) == 0 || len(sets) > encodedAddressSize {
+		return 0
+	}
+	a := newWordAutomaton(sets)
+	// dist is the probability of each state after the blocks so far
+	dist := map[int]float64{0: 1}
+	for _, b := range addressBlocks(net) {
+		total, _ := new(big.Float).SetInt(new(big.Int).Sub(b.hi, b.lo)).Float64()
+		next := make(map[int]float64)
+		for i, p := range dist {
+			counts := make(map[int]float64)
+			a.countBelow(counts, 1, b.hi, b.size, i)
+			a.countBelow(counts, -1, b.lo, b.size, i)
+			for t, n := range counts {
+				next[t] += p * n / total
+			}
+		}
+		dist = next
+	}
+	return dist[a.found]
+}
+
+// anyProbability returns the probability that at least one of
+// the independent events with the given probabilities happens
+func anyProbability(ps []float64) float64 {
+	none := 1.0
+	for _, p := range ps {
+		none *= 1 - p
+	}
+	return 1 - none
+}
+
+// Difficulty is the difficulty of finding an address
+// which matches the patterns of a vanity search
+type Difficulty struct {
+	// Probability is the probability that a random address matches
+	Probability float64
+}
+
+// Difficulty returns the difficulty of finding an
+// address which matches the patterns of the options
+func (o SearchOptions) Difficulty() (*Difficulty, error) {
+	p, err := o.Probability()
+	if err != nil {
+		return nil, err
+	}
+	if p <= 0 {
+		return nil, fmt.Errorf("no address can match")
+	}
+	return &Difficulty{Probability: p}, nil
+}
+
+// ExpectedAttempts returns the expected number of attempts to find a match
+func (d *Difficulty) ExpectedAttempts() float64 {
+	return 1 / d.Probability
+}
+
+// Attempts returns the number of attempts after which a match
+// was found with probability q
+func (d *Difficulty) Attempts(q float64) float64 {
+	if d.Probability >= 1 {
+		return 1
+	}
+	return math.Log1p(-q) / math.Log1p(-d.Probability)
+}
+
+// Found returns the probability that a match was found after
+// the given number of attempts
+func (d *Difficulty) Found(attempts uint64) float64 {
+	return -math.Expm1(float64(attempts) * math.Log1p(-d.Probability))
+}
+
+// measureMatcher checks addresses like the matcher of a search,
+// so that each attempt costs as much as in the search, but never
+// reports a match
+type measureMatcher struct {
+	Matcher
+}
+
+func (m measureMatcher) Match(address []byte) bool {
+	m.Matcher.Match(address)
+	return false
+}
+
+// MeasureRate runs the search described by opts, which never finds an
+// address, for the given duration, or until the first batch of keys was
+// checked if that takes longer, and returns the number of attempts per
+// second. Only the matcher, the network, the workers and the split keys
+// of opts are used.
+func MeasureRate(ctx context.Context, opts SearchOptions, d time.Duration) (float64, error) {
+	m, _, err := opts.matcher()
+	if err != nil {
+		return 0, err
+	}
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var attempts uint64
+	start := time.Now()
+	results, err := Search(searchCtx, SearchOptions{
+		Network:       opts.Network,
+		Matcher:       measureMatcher{m},
+		Workers:       opts.Workers,
+		Attempts:      &attempts,
+		SplitSpendKey: opts.SplitSpendKey,
+		SplitViewKey:  opts.SplitViewKey,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// Attempts are counted per batch, so measure
+	// at least until the first batch was checked
+	deadline := time.After(d)
+	poll := time.NewTicker(time.Millisecond)
+	defer poll.Stop()
+	for expired := false; !expired || atomic.LoadUint64(&attempts) == 0; {
+		select {
+		case <-ctx.Done():
+			cancel()
+			for range results {
+			}
+			return 0, ctx.Err()
+		case <-deadline:
+			expired = true
+		case <-poll.C:
+		}
+	}
+	rate := float64(atomic.LoadUint64(&attempts)) / time.Since(start).Seconds()
+	cancel()
+	// Wait for all workers to exit
+	for range results {
+	}
+	return rate, nil
+}
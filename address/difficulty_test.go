@@ -0,0 +1,365 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCountBelow(t *testing.T) {
+	sets := []*digitSet{charDigits('B', true), nil, charDigits('z', false)}
+	// Brute-force all values of 3 digits
+	matches := func(v int) bool {
+		digits := []int{v / 58 / 58, v / 58 % 58, v % 58}
+		for i, s := range sets {
+			if s != nil && !s[digits[i]] {
+				return false
+			}
+		}
+		return true
+	}
+	var count int64
+	for x := 0; ; x++ {
+		if got := countBelow(big.NewInt(int64(x)), sets).Int64(); got != count {
+			t.Fatalf("%d: got %d values, expected %d", x, got, count)
+		}
+		if x == 58*58*58 {
+			break
+		}
+		if matches(x) {
+			count++
+		}
+	}
+	if got := countBelow(big.NewInt(1<<40), sets).Int64(); got != count {
+		t.Fatalf("got %d values above all values, expected %d", got, count)
+	}
+}
+
+func TestPositionProbability(t *testing.T) {
+	for _, net := range Networks() {
+		for pos := 0; pos < encodedAddressSize; pos++ {
+			var sum float64
+			for _, c := range alphabet {
+				sum += wordProbability(net, []byte{c}, pos, false)
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Fatalf("%s: got probabilities summing up to %f at position %d", net, sum, pos)
+			}
+			if p := wordProbability(net, []byte("0"), pos, false); p != 0 {
+				t.Fatalf("%s: got probability %f of '0' at position %d", net, p, pos)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		word string
+		pos  int
+		p    float64
+	}{
+		{"4", 0, 1},
+		{"8", 0, 0},
+		{"C", 1, 0},
+		// The first character of a full block encodes at most 2^64-1
+		{"z", 11, 0},
+		// The last block only encodes 5 bytes
+		{"z", encodedAddressSize - 7, 0},
+		{"a", 20, 1.0 / 58},
+		{"a", encodedAddressSize, 0},
+	} {
+		if p := wordProbability(Mainnet, []byte(tc.word), tc.pos, false); math.Abs(p-tc.p) > 1e-3 {
+			t.Fatalf("%q at position %d: got probability %f, expected %f", tc.word, tc.pos, p, tc.p)
+		}
+	}
+
+	if cs, ci := wordProbability(Mainnet, []byte("ab"), 20, false), wordProbability(Mainnet, []byte("ab"), 20, true); math.Abs(ci-4*cs) > 1e-9 {
+		t.Fatalf("got case-insensitive probability %g, expected %g", ci, 4*cs)
+	}
+}
+
+func TestPositionProbabilitySampled(t *testing.T) {
+	// Compare the probabilities of the first character after
+	// the network characters to their frequencies in actual keys
+	const n = 1 << 16
+	w := newKeyWalker(h2b(fixtures[0].privSpendHex), searchBatchSize)
+	counts := make(map[byte]int)
+	var block [fullEncodedBlockSize]byte
+	for i := 0; i < n/searchBatchSize; i++ {
+		w.next()
+		for j := range w.pubs {
+			encodeFirstBlock(&block, Mainnet.StandardPrefix, w.pubs[j][:])
+			counts[block[2]]++
+		}
+	}
+	for _, c := range alphabet {
+		p := wordProbability(Mainnet, []byte{c}, 2, false)
+		// Allow for 6 standard deviations
+		if tolerance := 6 * math.Sqrt(n*p*(1-p)); math.Abs(float64(counts[c])-n*p) > tolerance+1 {
+			t.Fatalf("%q: got %d occurrences, expected %.0f", c, counts[c], n*p)
+		}
+	}
+}
+
+func TestWordAutomaton(t *testing.T) {
+	// Compare the states reached by all values below x
+	// to those of the digits of each value
+	const size = 3
+	words := [][]*digitSet{
+		{charDigits('a', false), charDigits('a', false)},
+		{charDigits('a', false), charDigits('b', true), charDigits('a', false)},
+	}
+	for _, sets := range words {
+		a := newWordAutomaton(sets)
+		for _, start := range []int{0, a.step(0, bytes.IndexByte(alphabet, 'a'))} {
+			for _, x := range []int64{0, 1, 33*58*58 + 33*58 + 33, 195112, 195113} {
+				counts := make(map[int]float64)
+				a.countBelow(counts, 1, big.NewInt(x), size, start)
+				want := make(map[int]float64)
+				for v := int64(0); v < x && v < 195112; v++ {
+					i := start
+					for _, d := range []int64{v / (58 * 58), v / 58 % 58, v % 58} {
+						i = a.step(i, int(d))
+					}
+					want[i]++
+				}
+				for i := range a.states {
+					if counts[i] != want[i] {
+						t.Fatalf("%d, %d, %d: got %g values in state %d, expected %g", len(sets), start, x, counts[i], i, want[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestContainsProbability(t *testing.T) {
+	// A word as long as an address can only be at position 0
+	sets := make([]*digitSet, encodedAddressSize)
+	for i, c := range []byte(fixtures[0].address) {
+		sets[i] = charDigits(c, true)
+	}
+	if got, want := containsProbability(Mainnet, sets), positionProbability(Mainnet, 0, sets); got != want {
+		t.Fatalf("got probability %g of the full address, expected %g", got, want)
+	}
+
+	// A self-overlapping word is less likely than the sum over its
+	// positions, which counts addresses with "aaa" twice
+	word := []*digitSet{charDigits('a', false), charDigits('a', false)}
+	var sum float64
+	for pos := 0; pos+len(word) <= encodedAddressSize; pos++ {
+		sum += positionProbability(Mainnet, pos, word)
+	}
+	if p := containsProbability(Mainnet, word); !(p < sum && p > 0.95*sum) {
+		t.Fatalf("got probability %g, expected a bit less than %g", p, sum)
+	}
+
+	// Compare the probability to the frequency in actual addresses
+	const n = 1 << 13
+	w := newKeyWalker(h2b(fixtures[0].privSpendHex), searchBatchSize)
+	c := newCandidate()
+	m := NewContainsMatcher([]byte("Ab"), true)
+	var count int
+	for i := 0; i < n/searchBatchSize; i++ {
+		w.next()
+		for j := range w.pubs {
+			w.privateKeyTo(&c.spendPriv, j)
+			c.spendPub = w.pubs[j]
+			c.deriveViewKey()
+			c.encodeAddress(Mainnet.StandardPrefix)
+			if m.Match(c.address[:]) {
+				count++
+			}
+		}
+	}
+	p, _ := probability(m, Mainnet)
+	// Allow for 6 standard deviations
+	if tolerance := 6 * math.Sqrt(n*p*(1-p)); math.Abs(float64(count)-n*p) > tolerance {
+		t.Fatalf("got %d addresses with the word, expected %.0f", count, n*p)
+	}
+}
+
+func TestSearchOptionsProbability(t *testing.T) {
+	opts := func(o SearchOptions) SearchOptions {
+		o.Network = Mainnet
+		return o
+	}
+	prefix, err := opts(SearchOptions{Prefix: []byte("ab")}).Probability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffix, err := opts(SearchOptions{Suffix: []byte("ab")}).Probability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contains, err := opts(SearchOptions{Contains: []byte("ab")}).Probability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(prefix > 0 && suffix > 0 && contains > 50*suffix) {
+		t.Fatalf("got probabilities %g (prefix), %g (suffix) and %g (contains)", prefix, suffix, contains)
+	}
+	if p, err := opts(SearchOptions{Patterns: [][]byte{[]byte("ab"), []byte("cd")}}).Probability(); err != nil || p < 1.9*prefix {
+		t.Fatalf("got probability %g of two patterns, expected about %g", p, 2*prefix)
+	}
+
+	re, err := NewRegexMatcher("^4[0-9AB]ab", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := opts(SearchOptions{Regex: re}).Probability(); err != nil || math.Abs(p-prefix) > 1e-12 {
+		t.Fatalf("got probability %g of regular expression, expected %g", p, prefix)
+	}
+	if re, err = NewRegexMatcher("ab$", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := opts(SearchOptions{Regex: re}).Probability(); err == nil {
+		t.Fatal("expected error for unanchored regular expression")
+	}
+}
+
+func TestNewWithOptionsSuffixContains(t *testing.T) {
+	for _, opts := range []SearchOptions{
+		{Suffix: []byte("a")},
+		{Contains: []byte("Ab"), IgnoreCase: true},
+	} {
+		opts.Network = Mainnet
+		opts.Workers = 2
+		res, err := NewWithOptions(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.EqualFold(res.Match, res.Pattern) {
+			t.Fatalf("got match %q of pattern %q", res.Match, res.Pattern)
+		}
+		if !bytes.Contains(res.Address, res.Match) {
+			t.Fatalf("address %s does not contain match %q", res.Address, res.Match)
+		}
+		if len(opts.Suffix) > 0 && !bytes.HasSuffix(res.Address, res.Match) {
+			t.Fatalf("address %s does not end with match %q", res.Address, res.Match)
+		}
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	opts := SearchOptions{Network: Mainnet, Prefix: []byte("abc")}
+	d, err := opts.Difficulty()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := opts.Probability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ExpectedAttempts() != 1/p {
+		t.Fatalf("got %f expected attempts, expected %f", d.ExpectedAttempts(), 1/p)
+	}
+	for _, q := range []float64{0.5, 0.9} {
+		n := d.Attempts(q)
+		if got := d.Found(uint64(n)); math.Abs(got-q) > 0.001 {
+			t.Fatalf("got probability %f after %f attempts, expected %f", got, n, q)
+		}
+	}
+
+	if _, err := (SearchOptions{Network: Mainnet, Prefix: []byte("0")}).Difficulty(); err == nil {
+		t.Fatal("expected impossible prefix to be rejected")
+	}
+}
+
+func TestSearchAttempts(t *testing.T) {
+	// Stop the search once the workers checked a batch,
+	// however long that takes
+	var attempts uint64
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := Search(ctx, SearchOptions{
+		Network:  Mainnet,
+		Matcher:  BlockMatcherFunc(func([]byte) bool { return false }),
+		Attempts: &attempts,
+		Workers:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for atomic.LoadUint64(&attempts) < searchBatchSize {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	for range results {
+	}
+	if n := atomic.LoadUint64(&attempts); n%searchBatchSize != 0 {
+		t.Fatalf("got %d attempts, expected a multiple of %d", n, searchBatchSize)
+	}
+
+	// The rate is measured until at least one batch was checked,
+	// even if that takes longer than the given duration, with the
+	// matcher of the search, which never matches
+	var checked uint64
+	rate, err := MeasureRate(context.Background(), SearchOptions{
+		Network: Mainnet,
+		Matcher: MatcherFunc(func(address []byte) bool {
+			atomic.AddUint64(&checked, 1)
+			return true
+		}),
+		Workers: 1,
+	}, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate <= 0 {
+		t.Fatalf("got rate %f", rate)
+	}
+	if n := atomic.LoadUint64(&checked); n < searchBatchSize {
+		t.Fatalf("got %d full addresses checked, expected at least %d", n, searchBatchSize)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		opts SearchOptions
+		err  string
+	}{
+		{SearchOptions{Network: Mainnet, Prefix: []byte("abO")}, "character 'O' at position 5 is not in Monero base58"},
+		{SearchOptions{Network: Mainnet, Prefix: []byte("0")}, "character '0' at position 3 is not in Monero base58"},
+		{SearchOptions{Network: Mainnet, Prefix: []byte("o"), IgnoreCase: true}, ""},
+		{SearchOptions{Network: Mainnet, Prefix: []byte("l"), IgnoreCase: true}, ""},
+		{SearchOptions{Network: Mainnet, Prefix: bytes.Repeat([]byte("a"), 94)}, `prefix "` + strings.Repeat("a", 94) + `" is longer than an address`},
+		{SearchOptions{Network: Mainnet, Patterns: [][]byte{[]byte("ab"), []byte("I")}}, "character 'I' at position 3 is not in Monero base58"},
+		{SearchOptions{Network: Mainnet, Suffix: []byte("ab")}, ""},
+		{SearchOptions{Network: Mainnet, Contains: []byte("aOb")}, "character 'O' at position 2 of the word is not in Monero base58"},
+		{SearchOptions{Network: Mainnet, Regex: mustRegexMatcher(t, "^4C")}, "regular expression ^4C can never match a mainnet address"},
+	} {
+		_, _, err := tc.opts.matcher()
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Fatalf("%+v: got error %v, expected %q", tc.opts, err, tc.err)
+		}
+	}
+
+	// The prefix starts after the characters determined by the
+	// network byte, all characters can appear at its first position
+	for _, net := range Networks() {
+		for _, c := range alphabet {
+			if err := ValidatePrefix(net, []byte{c}, false); err != nil {
+				t.Fatalf("%s: got error %q", net.Name, err)
+			}
+		}
+	}
+	want := "character 'C' at position 2 can never appear in a mainnet address"
+	if err := validateWord(Mainnet, "word", []byte("4C"), 0, false); err == nil || err.Error() != want {
+		t.Fatalf("got error %v, expected %q", err, want)
+	}
+	if err := validateWord(Mainnet, "suffix", []byte("z"), encodedAddressSize-7, false); err == nil {
+		t.Fatal("expected lowercase letter at the start of the last block to be rejected")
+	}
+}
+
+func mustRegexMatcher(t *testing.T, expr string) *RegexMatcher {
+	m, err := NewRegexMatcher(expr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
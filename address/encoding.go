@@ -1,59 +1,136 @@
 package address
 
 import (
-	"math/big"
+	"fmt"
 )
 
+const (
+	fullBlockSize        = 8
+	fullEncodedBlockSize = 11
+)
+
+var (
+	alphabet          = []byte("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+	encodedBlockSizes = []int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+)
+
+// encodeBlock converts a block of up to 8 bytes into
+// encodedBlockSizes[len(block)] Base58 characters in dst
+func encodeBlock(dst, block []byte) {
+	var num uint64
+	for _, b := range block {
+		num = num<<8 | uint64(b)
+	}
+	for i := encodedBlockSizes[len(block)] - 1; i >= 0; i-- {
+		dst[i] = alphabet[num%uint64(len(alphabet))]
+		num /= uint64(len(alphabet))
+	}
+}
+
 // Based on https://github.com/moneromooo-monero/monero-wallet-generator/blob/master/monero-wallet-generator.html
 // base58encode converts data into Base58-format
 func base58encode(data []byte) []byte {
-	const (
-		fullBlockSize        = 8
-		fullEncodedBlockSize = 11
-	)
-	var (
-		alphabet          = []byte("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
-		encodedBlockSizes = []int{0, 2, 3, 5, 6, 7, 9, 10, 11}
-	)
-
-	encodeBlock := func(data, buf []byte, index int) []byte {
-		lenAlphabet := big.NewInt(int64(len(alphabet)))
-		num := big.NewInt(0).SetBytes(data)
-		rem := big.NewInt(0)
-
-		i := encodedBlockSizes[len(data)] - 1
-		for num.Cmp(big.NewInt(0)) == 1 {
-			num.QuoRem(num, lenAlphabet, rem)
-			buf[index+i] = alphabet[int(rem.Int64())]
-			i--
-		}
-
-		return buf
-	}
+	fullBlockCount := len(data) / fullBlockSize
+	lastBlockSize := len(data) % fullBlockSize
+	res := make([]byte, fullBlockCount*fullEncodedBlockSize+encodedBlockSizes[lastBlockSize])
+	base58encodeTo(res, data)
+	return res
+}
 
+// base58encodeTo is like base58encode but writes into dst,
+// which must be large enough to hold the encoded data
+func base58encodeTo(dst, data []byte) {
 	fullBlockCount := len(data) / fullBlockSize
 	lastBlockSize := len(data) % fullBlockSize
-	resSize := fullBlockCount*fullEncodedBlockSize + encodedBlockSizes[lastBlockSize]
-	res := make([]byte, resSize)
-	for i := 0; i < resSize; i++ {
-		res[i] = alphabet[0]
-	}
 
 	for i := 0; i < fullBlockCount; i++ {
-		res = encodeBlock(
+		encodeBlock(
+			dst[i*fullEncodedBlockSize:],
 			data[i*fullBlockSize:i*fullBlockSize+fullBlockSize],
-			res,
-			i*fullEncodedBlockSize,
 		)
 	}
 
 	if lastBlockSize > 0 {
-		res = encodeBlock(
-			data[fullBlockCount*fullBlockSize:fullBlockCount*fullBlockSize+lastBlockSize],
-			res,
-			fullBlockCount*fullEncodedBlockSize,
+		encodeBlock(
+			dst[fullBlockCount*fullEncodedBlockSize:],
+			data[fullBlockCount*fullBlockSize:],
 		)
 	}
+}
 
-	return res
+// encodeFirstBlock encodes the first block of an address, which consists
+// of the network byte and the first 7 bytes of the public spend key.
+// It determines the first 11 characters of the address.
+func encodeFirstBlock(dst *[fullEncodedBlockSize]byte, netBytePrefix byte, pubSpend PublicKey) {
+	var block [fullBlockSize]byte
+	block[0] = netBytePrefix
+	copy(block[1:], pubSpend)
+	encodeBlock(dst[:], block[:])
+}
+
+// base58decode converts Base58-formatted data back into its raw form
+func base58decode(data []byte) ([]byte, error) {
+	var digits [256]int
+	for i := range digits {
+		digits[i] = -1
+	}
+	for i, c := range alphabet {
+		digits[c] = i
+	}
+
+	decodeBlock := func(data, buf []byte) error {
+		var num uint64
+		for _, c := range data {
+			digit := digits[c]
+			if digit < 0 {
+				return fmt.Errorf("invalid character %q", c)
+			}
+			if num > (^uint64(0)-uint64(digit))/uint64(len(alphabet)) {
+				return fmt.Errorf("block %q overflows", data)
+			}
+			num = num*uint64(len(alphabet)) + uint64(digit)
+		}
+		if len(buf) < fullBlockSize && num>>(8*uint(len(buf))) != 0 {
+			return fmt.Errorf("block %q overflows", data)
+		}
+		for i := len(buf) - 1; i >= 0; i-- {
+			buf[i] = byte(num)
+			num >>= 8
+		}
+		return nil
+	}
+
+	fullBlockCount := len(data) / fullEncodedBlockSize
+	lastEncodedBlockSize := len(data) % fullEncodedBlockSize
+	lastBlockSize := -1
+	for size, encodedSize := range encodedBlockSizes {
+		if encodedSize == lastEncodedBlockSize {
+			lastBlockSize = size
+			break
+		}
+	}
+	if lastBlockSize < 0 {
+		return nil, fmt.Errorf("invalid encoded length %d", len(data))
+	}
+
+	res := make([]byte, fullBlockCount*fullBlockSize+lastBlockSize)
+	for i := 0; i < fullBlockCount; i++ {
+		if err := decodeBlock(
+			data[i*fullEncodedBlockSize:i*fullEncodedBlockSize+fullEncodedBlockSize],
+			res[i*fullBlockSize:i*fullBlockSize+fullBlockSize],
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if lastBlockSize > 0 {
+		if err := decodeBlock(
+			data[fullBlockCount*fullEncodedBlockSize:],
+			res[fullBlockCount*fullBlockSize:],
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
 }
@@ -0,0 +1,47 @@
+package address
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewPaymentID returns a new random payment ID
+func NewPaymentID() ([]byte, error) {
+	id := make([]byte, paymentIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate payment ID: %s", err.Error())
+	}
+	return id, nil
+}
+
+// NewIntegrated returns the integrated address
+// of a standard address and an 8-byte payment ID
+func NewIntegrated(addr *Address, paymentID []byte) (*Address, error) {
+	if addr.Type != Standard {
+		return nil, fmt.Errorf("expected a standard address, got a %s address", addr.Type)
+	}
+	if len(paymentID) != paymentIDSize {
+		return nil, fmt.Errorf("invalid payment ID length %d, expected %d", len(paymentID), paymentIDSize)
+	}
+	return &Address{
+		Network:   addr.Network,
+		Type:      Integrated,
+		SpendKey:  addr.SpendKey,
+		ViewKey:   addr.ViewKey,
+		PaymentID: append([]byte(nil), paymentID...),
+	}, nil
+}
+
+// Split returns the standard address and the
+// payment ID an integrated address consists of
+func (a *Address) Split() (*Address, []byte, error) {
+	if a.Type != Integrated {
+		return nil, nil, fmt.Errorf("expected an integrated address, got a %s address", a.Type)
+	}
+	return &Address{
+		Network:  a.Network,
+		Type:     Standard,
+		SpendKey: a.SpendKey,
+		ViewKey:  a.ViewKey,
+	}, a.PaymentID, nil
+}
@@ -1,14 +1,12 @@
 package address
 
 import (
+	"bytes"
 	"crypto/rand"
-	"encoding/binary"
 	"fmt"
-	"math/big"
 
 	"github.com/agl/ed25519/edwards25519"
 	"golang.org/x/crypto/ed25519"
-	"golang.org/x/crypto/sha3"
 )
 
 type PrivateKey []byte
@@ -43,81 +41,85 @@ func newSpendKeyPair() (*KeyPair, error) {
 	return &KeyPair{priv, pub}, nil
 }
 
-// nextSpendKeyPairMaker returns a func to generate
-// a new key pair using an already existing one.
-// The previous key pair will be overwritten.
-func nextSpendKeyPairMaker(p *KeyPair) func() {
-	one := big.NewInt(1)
-	bn := big.NewInt(0)
-	return func() {
-		// Increase private key by one
-		bn.SetBytes(p.priv)
-		_ = bn.Add(bn, one)
-
-		var newPriv [32]byte
-		// bn.Bytes() might return a slice of length < 32 bytes
-		copy(newPriv[:], bn.Bytes())
-		newPriv[0] &= 248
-		newPriv[31] &= 127
-		newPriv[31] |= 64
-
-		p.priv = reduce(newPriv[:])
-		// TODO(leon): Reusing the old private key will give us a huge
-		// speed increase: p.pub = ed25519GeScalarMult(oldPriv, 2)
-		p.pub = private2Public(p.priv)
+// FromSeed returns the spend and view key pairs restored from
+// a 32-byte seed. Like Monero, it reduces the seed into a valid
+// private spend key first.
+func FromSeed(seed []byte) (*KeyPair, *KeyPair, error) {
+	if len(seed) != 32 {
+		return nil, nil, fmt.Errorf("invalid seed length %d, expected 32", len(seed))
 	}
+	return FromPrivateSpendKey(reduce(seed))
 }
 
-// nextSpendKeyPair generates a new key pair using
-// an already existing one. The previous key pair
-// will be overwritten.
-func nextSpendKeyPair(p *KeyPair) {
-	priv := p.priv
-	privUint64 := binary.BigEndian.Uint64(priv)
-	privUint64++
-
-	newPriv := priv
-	binary.BigEndian.PutUint64(newPriv[:32], privUint64)
-	newPriv[0] &= 248
-	newPriv[31] &= 127
-	newPriv[31] |= 64
-
-	p.priv = reduce(newPriv)
-	p.pub = private2Public(p.priv)
+// FromPrivateSpendKey returns the spend and view key pairs of an existing
+// private spend key. The key must be a canonical, reduced scalar.
+func FromPrivateSpendKey(priv PrivateKey) (*KeyPair, *KeyPair, error) {
+	if len(priv) != 32 {
+		return nil, nil, fmt.Errorf("invalid private spend key length %d, expected 32", len(priv))
+	}
+	if !isReduced(priv) {
+		return nil, nil, fmt.Errorf("private spend key is not a reduced scalar")
+	}
+	if bytes.Equal(priv, make([]byte, 32)) {
+		return nil, nil, fmt.Errorf("private spend key is zero")
+	}
+	priv = append(PrivateKey(nil), priv...)
+	spendKeyPair := &KeyPair{priv, private2Public(priv)}
+	viewKeyPair := makeViewKeyPair(priv)
+	return spendKeyPair, viewKeyPair, nil
 }
 
 // makeViewKeyPair returns a view key pair based on a private spend key
 func makeViewKeyPair(p PrivateKey) *KeyPair {
-	// Hash private spend key using Keccak-256
-	h := sha3.NewLegacyKeccak256()
-	if _, err := h.Write(p); err != nil {
-		panic(err)
-	}
-	// Important: Reduce to stay in finite field
-	priv := reduce(h.Sum(nil))
-	// Turn private into public key
-	pub := private2Public(priv)
-	return &KeyPair{priv, pub}
+	c := newCandidate()
+	copy(c.spendPriv[:], p)
+	c.deriveViewKey()
+	_, viewKeyPair := c.keyPairs()
+	return viewKeyPair
 }
 
 // Based on golang.org/x/crypto/ed25519
 // private2Public converts a private key into the associated public key
 func private2Public(priv PrivateKey) PublicKey {
+	var scalar, pub [32]byte
+	copy(scalar[:], priv)
+	private2PublicTo(&pub, &scalar)
+	return pub[:]
+}
+
+// private2PublicTo is like private2Public but writes into pub
+func private2PublicTo(pub, priv *[32]byte) {
+	var A edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&A, priv)
+	A.ToBytes(pub)
+}
+
+// isValidPublicKey reports whether pub decodes to a point on the curve
+func isValidPublicKey(pub PublicKey) bool {
+	if len(pub) != 32 {
+		return false
+	}
 	var A edwards25519.ExtendedGroupElement
-	var scalar [32]byte
-	copy(scalar[:], priv[:])
-	edwards25519.GeScalarMultBase(&A, &scalar)
+	var p [32]byte
+	copy(p[:], pub)
+	return A.FromBytes(&p)
+}
 
-	var pub [32]byte
-	A.ToBytes(&pub)
-	return pub[:]
+// isReduced reports whether scalar is reduced into the Ed25519 finite field
+func isReduced(scalar []byte) bool {
+	return len(scalar) == 32 && bytes.Equal(reduce(scalar), scalar)
 }
 
 // reduce ensures we stay in the Ed25519 finite field
 func reduce(scalar []byte) []byte {
-	var in [64]byte
-	copy(in[:32], scalar)
 	var out [32]byte
-	edwards25519.ScReduce(&out, &in)
+	reduceTo(&out, scalar)
 	return out[:]
 }
+
+// reduceTo is like reduce but writes into out
+func reduceTo(out *[32]byte, scalar []byte) {
+	var in [64]byte
+	copy(in[:], scalar)
+	edwards25519.ScReduce(out, &in)
+}
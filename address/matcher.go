@@ -0,0 +1,436 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Matcher decides whether an address matches a vanity pattern.
+// Addresses are Base58-encoded standard addresses.
+type Matcher interface {
+	// MatchBlock reports whether an address which starts with the
+	// given first encoded block of 11 characters might match. It is
+	// called for every candidate, before the view key and the checksum
+	// are derived, and must not reject any address Match accepts.
+	MatchBlock(block []byte) bool
+	// Match reports whether the full address matches
+	Match(address []byte) bool
+}
+
+// Finder is implemented by matchers which can tell which
+// part of a matching address matches which pattern
+type Finder interface {
+	// Find returns the pattern a matching address matches
+	// and the part of the address which matches it
+	Find(address []byte) ([]byte, []byte)
+}
+
+// estimator is implemented by matchers which know how likely it is that
+// a random address matches. The search difficulty is based on it.
+type estimator interface {
+	// probability returns the probability that a random standard
+	// address of the network matches, and whether it is known
+	probability(net Network) (float64, bool)
+}
+
+// find returns the pattern an address matches and the
+// part of the address which matches it, if m knows them
+func find(m Matcher, address []byte) ([]byte, []byte) {
+	if f, ok := m.(Finder); ok {
+		return f.Find(address)
+	}
+	return nil, nil
+}
+
+// probability returns the probability that a random standard
+// address of the network matches, if m knows it
+func probability(m Matcher, net Network) (float64, bool) {
+	if e, ok := m.(estimator); ok {
+		return e.probability(net)
+	}
+	return 0, false
+}
+
+// MatcherFunc matches addresses for which the function returns true.
+// It can only check full addresses.
+type MatcherFunc func(address []byte) bool
+
+// MatchBlock accepts all blocks
+func (f MatcherFunc) MatchBlock(block []byte) bool {
+	return true
+}
+
+// Match calls f(address)
+func (f MatcherFunc) Match(address []byte) bool {
+	return f(address)
+}
+
+// BlockMatcherFunc matches addresses whose first encoded block of
+// 11 characters the function returns true for. It is as cheap as
+// a prefix.
+type BlockMatcherFunc func(block []byte) bool
+
+// MatchBlock calls f(block)
+func (f BlockMatcherFunc) MatchBlock(block []byte) bool {
+	return f(block)
+}
+
+// Match calls f with the first block of the address
+func (f BlockMatcherFunc) Match(address []byte) bool {
+	return len(address) >= fullEncodedBlockSize && f(address[:fullEncodedBlockSize])
+}
+
+// NewPrefixMatcher returns a matcher for a literal prefix which starts
+// right after the characters determined by the network byte
+func NewPrefixMatcher(net Network, prefix []byte, ignoreCase bool) Matcher {
+	return &prefixMatcher{
+		offset:     net.leadingChars(),
+		prefix:     prefix,
+		ignoreCase: ignoreCase,
+	}
+}
+
+// NewPatternsMatcher returns a matcher for many literal prefixes like
+// NewPrefixMatcher, any one of which has to match. All prefixes are
+// checked at once.
+func NewPatternsMatcher(net Network, patterns [][]byte, ignoreCase bool) (Matcher, error) {
+	m := newTrieMatcher(net.leadingChars(), ignoreCase)
+	for _, p := range patterns {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("empty pattern")
+		}
+		m.add(p)
+	}
+	if m.size == 0 {
+		return nil, fmt.Errorf("no patterns")
+	}
+	return m, nil
+}
+
+// NewSuffixMatcher returns a matcher for a literal suffix
+func NewSuffixMatcher(suffix []byte, ignoreCase bool) Matcher {
+	return &suffixMatcher{
+		suffix:     suffix,
+		ignoreCase: ignoreCase,
+	}
+}
+
+// NewContainsMatcher returns a matcher for a literal
+// word which may appear anywhere in the address
+func NewContainsMatcher(word []byte, ignoreCase bool) Matcher {
+	return &containsMatcher{
+		word:       word,
+		ignoreCase: ignoreCase,
+	}
+}
+
+// All returns a matcher for addresses which all of the matchers match
+func All(matchers ...Matcher) Matcher {
+	if len(matchers) == 1 {
+		return matchers[0]
+	}
+	return allMatcher(matchers)
+}
+
+// Any returns a matcher for addresses which any of the matchers matches
+func Any(matchers ...Matcher) Matcher {
+	if len(matchers) == 1 {
+		return matchers[0]
+	}
+	return anyMatcher(matchers)
+}
+
+// prefixMatcher matches a literal prefix which starts right
+// after the characters determined by the network byte
+type prefixMatcher struct {
+	offset int
+	prefix []byte
+	// ignoreCase makes the prefix match in any casing
+	ignoreCase bool
+}
+
+// hasPrefix reports whether s begins with prefix
+func (m *prefixMatcher) hasPrefix(s, prefix []byte) bool {
+	if m.ignoreCase {
+		return len(s) >= len(prefix) && bytes.EqualFold(s[:len(prefix)], prefix)
+	}
+	return bytes.HasPrefix(s, prefix)
+}
+
+func (m *prefixMatcher) MatchBlock(block []byte) bool {
+	// The part of the prefix within the first block
+	// can be checked without encoding the full address
+	prefix := m.prefix
+	if len(prefix) > len(block)-m.offset {
+		prefix = prefix[:len(block)-m.offset]
+	}
+	return m.hasPrefix(block[m.offset:], prefix)
+}
+
+func (m *prefixMatcher) Match(address []byte) bool {
+	return m.hasPrefix(address[m.offset:], m.prefix)
+}
+
+func (m *prefixMatcher) Find(address []byte) ([]byte, []byte) {
+	return m.prefix, address[m.offset : m.offset+len(m.prefix)]
+}
+
+func (m *prefixMatcher) probability(net Network) (float64, bool) {
+	return wordProbability(net, m.prefix, m.offset, m.ignoreCase), true
+}
+
+// suffixMatcher matches a literal suffix. The suffix is encoded
+// from the checksum, so only full addresses can be checked.
+type suffixMatcher struct {
+	suffix []byte
+	// ignoreCase makes the suffix match in any casing
+	ignoreCase bool
+}
+
+func (m *suffixMatcher) MatchBlock(block []byte) bool {
+	return true
+}
+
+func (m *suffixMatcher) Match(address []byte) bool {
+	if len(address) < len(m.suffix) {
+		return false
+	}
+	s := address[len(address)-len(m.suffix):]
+	if m.ignoreCase {
+		return bytes.EqualFold(s, m.suffix)
+	}
+	return bytes.Equal(s, m.suffix)
+}
+
+func (m *suffixMatcher) Find(address []byte) ([]byte, []byte) {
+	return m.suffix, address[len(address)-len(m.suffix):]
+}
+
+func (m *suffixMatcher) probability(net Network) (float64, bool) {
+	return wordProbability(net, m.suffix, encodedAddressSize-len(m.suffix), m.ignoreCase), true
+}
+
+// containsMatcher matches a literal word anywhere in the address
+type containsMatcher struct {
+	word []byte
+	// ignoreCase makes the word match in any casing
+	ignoreCase bool
+}
+
+// index returns the index of the first instance of
+// the word in address, or -1 if it is not present
+func (m *containsMatcher) index(address []byte) int {
+	if !m.ignoreCase {
+		return bytes.Index(address, m.word)
+	}
+	for i := 0; i+len(m.word) <= len(address); i++ {
+		if bytes.EqualFold(address[i:i+len(m.word)], m.word) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *containsMatcher) MatchBlock(block []byte) bool {
+	return true
+}
+
+func (m *containsMatcher) Match(address []byte) bool {
+	return m.index(address) >= 0
+}
+
+func (m *containsMatcher) Find(address []byte) ([]byte, []byte) {
+	i := m.index(address)
+	return m.word, address[i : i+len(m.word)]
+}
+
+func (m *containsMatcher) probability(net Network) (float64, bool) {
+	sets := make([]*digitSet, len(m.word))
+	for i, c := range m.word {
+		sets[i] = charDigits(c, m.ignoreCase)
+	}
+	return containsProbability(net, sets), true
+}
+
+// trieMatcher matches any of many literal prefixes which start
+// right after the characters determined by the network byte
+type trieMatcher struct {
+	offset int
+	// ignoreCase makes the prefixes match in any casing
+	ignoreCase bool
+	root       trieNode
+	// size is the number of distinct prefixes
+	size int
+	// patterns are the distinct prefixes
+	patterns [][]byte
+}
+
+type trieNode struct {
+	children [128]*trieNode
+	// pattern is set if a prefix ends at this node
+	pattern []byte
+}
+
+func newTrieMatcher(offset int, ignoreCase bool) *trieMatcher {
+	return &trieMatcher{offset: offset, ignoreCase: ignoreCase}
+}
+
+// key returns the trie key of character b
+func (m *trieMatcher) key(b byte) byte {
+	if m.ignoreCase && 'A' <= b && b <= 'Z' {
+		b += 'a' - 'A'
+	}
+	return b & 127
+}
+
+// add adds prefix to the trie. Prefixes with
+// non-ASCII characters can never match.
+func (m *trieMatcher) add(prefix []byte) {
+	n := &m.root
+	for _, b := range prefix {
+		if b >= 128 {
+			return
+		}
+		k := m.key(b)
+		if n.children[k] == nil {
+			n.children[k] = &trieNode{}
+		}
+		n = n.children[k]
+	}
+	if n.pattern == nil {
+		n.pattern = prefix
+		m.size++
+		m.patterns = append(m.patterns, prefix)
+	}
+}
+
+// walk follows s through the trie. It returns the longest prefix of s
+// in the trie, and whether s ended before the trie did.
+func (m *trieMatcher) walk(s []byte) ([]byte, bool) {
+	var longest []byte
+	n := &m.root
+	for _, b := range s {
+		if n.pattern != nil {
+			longest = n.pattern
+		}
+		if b >= 128 {
+			return longest, false
+		}
+		if n = n.children[m.key(b)]; n == nil {
+			return longest, false
+		}
+	}
+	if n.pattern != nil {
+		longest = n.pattern
+	}
+	return longest, true
+}
+
+func (m *trieMatcher) MatchBlock(block []byte) bool {
+	longest, more := m.walk(block[m.offset:])
+	return longest != nil || more
+}
+
+func (m *trieMatcher) Match(address []byte) bool {
+	longest, _ := m.walk(address[m.offset:])
+	return longest != nil
+}
+
+func (m *trieMatcher) Find(address []byte) ([]byte, []byte) {
+	longest, _ := m.walk(address[m.offset:])
+	return longest, address[m.offset : m.offset+len(longest)]
+}
+
+func (m *trieMatcher) probability(net Network) (float64, bool) {
+	var ps []float64
+	for _, p := range m.patterns {
+		ps = append(ps, wordProbability(net, p, m.offset, m.ignoreCase))
+	}
+	return anyProbability(ps), true
+}
+
+// allMatcher matches addresses which all of its matchers match
+type allMatcher []Matcher
+
+func (m allMatcher) MatchBlock(block []byte) bool {
+	for _, mm := range m {
+		if !mm.MatchBlock(block) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m allMatcher) Match(address []byte) bool {
+	for _, mm := range m {
+		if !mm.Match(address) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find reports the pattern of the first matcher which knows it
+func (m allMatcher) Find(address []byte) ([]byte, []byte) {
+	for _, mm := range m {
+		if pattern, match := find(mm, address); pattern != nil {
+			return pattern, match
+		}
+	}
+	return nil, nil
+}
+
+func (m allMatcher) probability(net Network) (float64, bool) {
+	p := 1.0
+	for _, mm := range m {
+		pp, ok := probability(mm, net)
+		if !ok {
+			return 0, false
+		}
+		p *= pp
+	}
+	return p, true
+}
+
+// anyMatcher matches addresses which any of its matchers matches
+type anyMatcher []Matcher
+
+func (m anyMatcher) MatchBlock(block []byte) bool {
+	for _, mm := range m {
+		if mm.MatchBlock(block) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m anyMatcher) Match(address []byte) bool {
+	for _, mm := range m {
+		if mm.Match(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find reports the pattern of the first matcher which matches
+func (m anyMatcher) Find(address []byte) ([]byte, []byte) {
+	for _, mm := range m {
+		if mm.Match(address) {
+			return find(mm, address)
+		}
+	}
+	return nil, nil
+}
+
+func (m anyMatcher) probability(net Network) (float64, bool) {
+	var ps []float64
+	for _, mm := range m {
+		p, ok := probability(mm, net)
+		if !ok {
+			return 0, false
+		}
+		ps = append(ps, p)
+	}
+	return anyProbability(ps), true
+}
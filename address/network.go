@@ -0,0 +1,61 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Network describes a Monero network by the prefix bytes
+// its addresses are encoded with
+type Network struct {
+	Name             string
+	StandardPrefix   byte
+	IntegratedPrefix byte
+	SubaddressPrefix byte
+}
+
+var (
+	Mainnet  = Network{"mainnet", 18, 19, 42}
+	Testnet  = Network{"testnet", 53, 54, 63}
+	Stagenet = Network{"stagenet", 24, 25, 36}
+)
+
+// Networks returns all known networks
+func Networks() []Network {
+	return []Network{Mainnet, Testnet, Stagenet}
+}
+
+// NetworkByName returns the network with the given name
+func NetworkByName(name string) (Network, error) {
+	for _, n := range Networks() {
+		if strings.EqualFold(n.Name, name) {
+			return n, nil
+		}
+	}
+	return Network{}, fmt.Errorf("unknown network %q", name)
+}
+
+func (n Network) String() string {
+	return n.Name
+}
+
+// leadingChars returns the number of leading characters of a standard
+// address which are (at least partially) determined by the network byte.
+// A vanity prefix is matched right after those.
+func (n Network) leadingChars() int {
+	// The first base58 block encodes the network byte followed by the
+	// first 7 bytes of the public spend key into 11 characters. A
+	// character is determined by the network byte as long as the range
+	// of possible block values does not cover all 58 of its values.
+	lo := uint64(n.StandardPrefix) << 56
+	hi := lo | (1<<56 - 1)
+	weight := uint64(1)
+	for i := 0; i < 10; i++ {
+		weight *= 58
+	}
+	var count int
+	for ; weight > 0 && hi/weight-lo/weight < 57; weight /= 58 {
+		count++
+	}
+	return count
+}
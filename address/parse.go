@@ -0,0 +1,129 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Type is the type of a Monero address
+type Type int
+
+const (
+	Standard Type = iota
+	Integrated
+	Subaddress
+)
+
+const (
+	keySize       = 32
+	paymentIDSize = 8
+	checksumSize  = 4
+)
+
+func (t Type) String() string {
+	switch t {
+	case Standard:
+		return "standard"
+	case Integrated:
+		return "integrated"
+	case Subaddress:
+		return "subaddress"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// Address is a decoded Monero address
+type Address struct {
+	Network  Network
+	Type     Type
+	SpendKey PublicKey
+	ViewKey  PublicKey
+	// PaymentID is only set for integrated addresses
+	PaymentID []byte
+}
+
+// prefix returns the network byte the address is encoded with
+func (a *Address) prefix() byte {
+	switch a.Type {
+	case Integrated:
+		return a.Network.IntegratedPrefix
+	case Subaddress:
+		return a.Network.SubaddressPrefix
+	}
+	return a.Network.StandardPrefix
+}
+
+// String returns the Base58-encoded address
+func (a *Address) String() string {
+	buf := make([]byte, 0, 1+2*keySize+paymentIDSize+checksumSize)
+	buf = append(buf, a.prefix())
+	buf = append(buf, a.SpendKey...)
+	buf = append(buf, a.ViewKey...)
+	buf = append(buf, a.PaymentID...)
+	buf = append(buf, checksum(buf)...)
+	return string(base58encode(buf))
+}
+
+// Parse decodes an address and validates its network byte,
+// length, checksum and public keys
+func Parse(s string) (*Address, error) {
+	data, err := base58decode([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address: %s", err.Error())
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty address")
+	}
+
+	addr := &Address{}
+	if !lookupPrefix(data[0], addr) {
+		return nil, fmt.Errorf("unknown network byte %d", data[0])
+	}
+
+	size := 1 + 2*keySize + checksumSize
+	if addr.Type == Integrated {
+		size += paymentIDSize
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("invalid length %d of %s %s address, expected %d", len(data), addr.Network, addr.Type, size)
+	}
+
+	body, sum := data[:size-checksumSize], data[size-checksumSize:]
+	if !bytes.Equal(checksum(body), sum) {
+		return nil, fmt.Errorf("invalid checksum")
+	}
+
+	addr.SpendKey = PublicKey(body[1 : 1+keySize])
+	addr.ViewKey = PublicKey(body[1+keySize : 1+2*keySize])
+	if addr.Type == Integrated {
+		addr.PaymentID = body[1+2*keySize:]
+	}
+	if !isValidPublicKey(addr.SpendKey) {
+		return nil, fmt.Errorf("invalid public spend key")
+	}
+	if !isValidPublicKey(addr.ViewKey) {
+		return nil, fmt.Errorf("invalid public view key")
+	}
+
+	return addr, nil
+}
+
+// lookupPrefix sets the network and type of addr
+// based on the network byte it is encoded with
+func lookupPrefix(prefix byte, addr *Address) bool {
+	for _, n := range Networks() {
+		addr.Network = n
+		switch prefix {
+		case n.StandardPrefix:
+			addr.Type = Standard
+		case n.IntegratedPrefix:
+			addr.Type = Integrated
+		case n.SubaddressPrefix:
+			addr.Type = Subaddress
+		default:
+			continue
+		}
+		return true
+	}
+	return false
+}
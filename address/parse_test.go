@@ -0,0 +1,173 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		addr, err := Parse(fx.address)
+		if err != nil {
+			return err
+		}
+		if addr.Network != Mainnet || addr.Type != Standard || addr.PaymentID != nil {
+			return fmt.Errorf("got incorrect address metadata: %s %s", addr.Network, addr.Type)
+		}
+		if got := b2h(addr.SpendKey); got != fx.pubSpendHex {
+			return fmt.Errorf("got incorrect public spend key: %s", got)
+		}
+		if got := b2h(addr.ViewKey); got != fx.pubViewHex {
+			return fmt.Errorf("got incorrect public view key: %s", got)
+		}
+		if got := addr.String(); got != fx.address {
+			return fmt.Errorf("got incorrect address: %s", got)
+		}
+		return nil
+	}, t)
+}
+
+func TestParseNetworks(t *testing.T) {
+	for _, net := range Networks() {
+		_, _, address, err := New(net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := Parse(string(address))
+		if err != nil {
+			t.Fatalf("%s: %s", net, err.Error())
+		}
+		if addr.Network != net || addr.Type != Standard {
+			t.Fatalf("%s: got incorrect address metadata: %s %s", net, addr.Network, addr.Type)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	valid := fixtures[0].address
+	invalid := map[string]string{
+		"empty":            "",
+		"invalid char":     "0" + valid[1:],
+		"invalid length":   valid[:len(valid)-1],
+		"truncated":        valid[:len(valid)-11],
+		"invalid checksum": valid[:len(valid)-1] + "M",
+		"invalid prefix":   strings.Repeat("1", len(valid)),
+	}
+	for name, s := range invalid {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("%s: expected error when parsing %q", name, s)
+		}
+	}
+}
+
+func TestBase58RoundTrip(t *testing.T) {
+	for size := 0; size < 3*fullBlockSize; size++ {
+		data := bytes.Repeat([]byte{0xff}, size)
+		for i := range data {
+			data[i] -= byte(i * 13)
+		}
+		enc := base58encode(data)
+		dec, err := base58decode(enc)
+		if err != nil {
+			t.Fatalf("size %d: %s", size, err.Error())
+		}
+		if !bytes.Equal(dec, data) {
+			t.Fatalf("size %d: got %x, expected %x", size, dec, data)
+		}
+	}
+	// 11 characters which exceed 2^64
+	if _, err := base58decode([]byte("zzzzzzzzzzz")); err == nil {
+		t.Fatal("expected overflow error")
+	}
+}
+
+func TestBase58Encode(t *testing.T) {
+	for _, tc := range []struct {
+		data     []byte
+		expected string
+	}{
+		{nil, ""},
+		{[]byte{0xff}, "5Q"},
+		{make([]byte, fullBlockSize), "11111111111"},
+		{bytes.Repeat([]byte{0xff}, fullBlockSize), "jpXCZedGfVQ"},
+		{bytes.Repeat([]byte{0xff}, fullBlockSize+1), "jpXCZedGfVQ5Q"},
+	} {
+		if got := string(base58encode(tc.data)); got != tc.expected {
+			t.Fatalf("%x: got %q, expected %q", tc.data, got, tc.expected)
+		}
+	}
+}
+
+func TestEncodeFirstBlock(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		var block [fullEncodedBlockSize]byte
+		encodeFirstBlock(&block, Mainnet.StandardPrefix, h2b(fx.pubSpendHex))
+		if got := string(block[:]); got != fx.address[:fullEncodedBlockSize] {
+			return fmt.Errorf("got first block %q, expected %q", got, fx.address[:fullEncodedBlockSize])
+		}
+		return nil
+	}, t)
+}
+
+func TestParseSubaddress(t *testing.T) {
+	const s = "888tNkZrPN6JsEgekjMnABU4TBzc2Dt29EPAvkRxbANsAnjyPbb3iQ1YBRk1UXcdRsiKc9dhwMVgN5S9cQUiyoogDavup3H"
+	addr, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.Network != Mainnet || addr.Type != Subaddress {
+		t.Fatalf("got incorrect address metadata: %s %s", addr.Network, addr.Type)
+	}
+	if got := addr.String(); got != s {
+		t.Fatalf("got incorrect address: %s", got)
+	}
+}
+
+func TestIntegrated(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		std, err := Parse(fx.address)
+		if err != nil {
+			return err
+		}
+		paymentID, err := NewPaymentID()
+		if err != nil {
+			return err
+		}
+		integrated, err := NewIntegrated(std, paymentID)
+		if err != nil {
+			return err
+		}
+		s := integrated.String()
+		if len(s) != 106 || s[0] != '4' {
+			return fmt.Errorf("got incorrect integrated address: %s", s)
+		}
+
+		parsed, err := Parse(s)
+		if err != nil {
+			return err
+		}
+		if parsed.Type != Integrated || !bytes.Equal(parsed.PaymentID, paymentID) {
+			return fmt.Errorf("got incorrect integrated address: %s %x", parsed.Type, parsed.PaymentID)
+		}
+		addr, id, err := parsed.Split()
+		if err != nil {
+			return err
+		}
+		if addr.String() != fx.address || !bytes.Equal(id, paymentID) {
+			return fmt.Errorf("got incorrect split address: %s %x", addr, id)
+		}
+
+		if _, err := NewIntegrated(parsed, paymentID); err == nil {
+			return fmt.Errorf("expected error for integrated address")
+		}
+		if _, err := NewIntegrated(std, paymentID[1:]); err == nil {
+			return fmt.Errorf("expected error for invalid payment ID")
+		}
+		if _, _, err := std.Split(); err == nil {
+			return fmt.Errorf("expected error for standard address")
+		}
+		return nil
+	}, t)
+}
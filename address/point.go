@@ -0,0 +1,143 @@
+package address
+
+import (
+	"encoding/binary"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+// cachedPoint is a point (Y+X, Y-X, Z, 2dT) prepared
+// to be added to other points repeatedly
+type cachedPoint struct {
+	yPlusX, yMinusX, z, t2d edwards25519.FieldElement
+}
+
+// d2 is twice the curve constant d = -121665/121666
+var d2 = func() edwards25519.FieldElement {
+	num := edwards25519.FieldElement{121665}
+	den := edwards25519.FieldElement{121666}
+	var d edwards25519.FieldElement
+	edwards25519.FeInvert(&d, &den)
+	edwards25519.FeMul(&d, &d, &num)
+	edwards25519.FeNeg(&d, &d)
+	edwards25519.FeAdd(&d, &d, &d)
+	return d
+}()
+
+// newCachedPoint prepares p for repeated additions
+func newCachedPoint(p *edwards25519.ExtendedGroupElement) *cachedPoint {
+	var c cachedPoint
+	edwards25519.FeAdd(&c.yPlusX, &p.Y, &p.X)
+	edwards25519.FeSub(&c.yMinusX, &p.Y, &p.X)
+	edwards25519.FeCopy(&c.z, &p.Z)
+	edwards25519.FeMul(&c.t2d, &p.T, &d2)
+	return &c
+}
+
+// addPoint sets p = p + q using the unified addition formula
+// for extended coordinates (add-2008-hwcd-3)
+func addPoint(p *edwards25519.ExtendedGroupElement, q *cachedPoint) {
+	var a, b, c, d, e, f, g, h edwards25519.FieldElement
+	edwards25519.FeSub(&a, &p.Y, &p.X)
+	edwards25519.FeMul(&a, &a, &q.yMinusX)
+	edwards25519.FeAdd(&b, &p.Y, &p.X)
+	edwards25519.FeMul(&b, &b, &q.yPlusX)
+	edwards25519.FeMul(&c, &p.T, &q.t2d)
+	edwards25519.FeMul(&d, &p.Z, &q.z)
+	edwards25519.FeAdd(&d, &d, &d)
+
+	edwards25519.FeSub(&e, &b, &a)
+	edwards25519.FeSub(&f, &d, &c)
+	edwards25519.FeAdd(&g, &d, &c)
+	edwards25519.FeAdd(&h, &b, &a)
+
+	edwards25519.FeMul(&p.X, &e, &f)
+	edwards25519.FeMul(&p.Y, &g, &h)
+	edwards25519.FeMul(&p.T, &e, &h)
+	edwards25519.FeMul(&p.Z, &f, &g)
+}
+
+// keyWalker walks the keyspace in batches of consecutive private keys.
+// Every key costs a single point addition, and the public keys of a
+// batch share a single field inversion (Montgomery's trick).
+type keyWalker struct {
+	// base is the private key preceding the current batch,
+	// scalar the private key of point
+	base, scalar [32]byte
+	// batch is the batch size as a scalar
+	batch [32]byte
+	point edwards25519.ExtendedGroupElement
+	step  *cachedPoint
+
+	points []edwards25519.ExtendedGroupElement
+	// products holds the running products of the Z coordinates
+	products []edwards25519.FieldElement
+	// pubs holds the public keys of the current batch
+	pubs [][32]byte
+}
+
+// newKeyWalker returns a walker which starts right
+// after priv and produces batchSize keys at a time
+func newKeyWalker(priv PrivateKey, batchSize int) *keyWalker {
+	w := &keyWalker{
+		points:   make([]edwards25519.ExtendedGroupElement, batchSize),
+		products: make([]edwards25519.FieldElement, batchSize),
+		pubs:     make([][32]byte, batchSize),
+	}
+	copy(w.scalar[:], priv)
+	binary.LittleEndian.PutUint32(w.batch[:], uint32(batchSize))
+	edwards25519.GeScalarMultBase(&w.point, &w.scalar)
+
+	var one [32]byte
+	one[0] = 1
+	var base edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&base, &one)
+	w.step = newCachedPoint(&base)
+	return w
+}
+
+// addBase adds base to the public keys of all keys the walker
+// produces. The keys become offsets to the private key of base.
+func (w *keyWalker) addBase(base *edwards25519.ExtendedGroupElement) {
+	addPoint(&w.point, newCachedPoint(base))
+}
+
+// next advances to the next batch of keys
+func (w *keyWalker) next() {
+	var one [32]byte
+	one[0] = 1
+	w.base = w.scalar
+	edwards25519.ScMulAdd(&w.scalar, &one, &w.scalar, &w.batch)
+
+	var acc edwards25519.FieldElement
+	edwards25519.FeOne(&acc)
+	for i := range w.points {
+		addPoint(&w.point, w.step)
+		w.points[i] = w.point
+		// products[i] = Z_0 * ... * Z_(i-1)
+		w.products[i] = acc
+		edwards25519.FeMul(&acc, &acc, &w.point.Z)
+	}
+
+	// inv = 1 / (Z_0 * ... * Z_i), starting with the last point
+	var inv, recip, x, y edwards25519.FieldElement
+	edwards25519.FeInvert(&inv, &acc)
+	for i := len(w.points) - 1; i >= 0; i-- {
+		p := &w.points[i]
+		edwards25519.FeMul(&recip, &inv, &w.products[i])
+		edwards25519.FeMul(&inv, &inv, &p.Z)
+
+		edwards25519.FeMul(&x, &p.X, &recip)
+		edwards25519.FeMul(&y, &p.Y, &recip)
+		edwards25519.FeToBytes(&w.pubs[i], &y)
+		w.pubs[i][31] ^= edwards25519.FeIsNegative(&x) << 7
+	}
+}
+
+// privateKeyTo writes the i-th private key of the current batch into priv
+func (w *keyWalker) privateKeyTo(priv *[32]byte, i int) {
+	var one, offset [32]byte
+	one[0] = 1
+	binary.LittleEndian.PutUint32(offset[:], uint32(i+1))
+	edwards25519.ScMulAdd(priv, &one, &w.base, &offset)
+}
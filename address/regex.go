@@ -0,0 +1,194 @@
+package address
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"unicode"
+)
+
+// RegexMatcher matches addresses against a regular expression.
+// The expression is matched against the full address.
+type RegexMatcher struct {
+	re *regexp.Regexp
+	// expr is the expression as given, without the flag to ignore case
+	expr string
+	// classes are the characters allowed at each of the leading
+	// positions of an address if the expression is anchored
+	classes []charClass
+}
+
+// NewRegexMatcher compiles a regular expression in Go syntax, e.g.
+// "^4[0-9AB](Team|TEAM)", which matches in any casing if ignoreCase
+// is set. If the expression is anchored at the start, the leading
+// characters it allows are checked on the first encoded block,
+// before the full address is derived.
+func NewRegexMatcher(expr string, ignoreCase bool) (*RegexMatcher, error) {
+	source := expr
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	m := &RegexMatcher{re: re, expr: source}
+	if prog.StartCond()&syntax.EmptyBeginText != 0 {
+		m.classes, _ = leadingClasses(parsed)
+	}
+	return m, nil
+}
+
+// String returns the regular expression as given
+func (m *RegexMatcher) String() string {
+	return m.expr
+}
+
+func (m *RegexMatcher) MatchBlock(block []byte) bool {
+	for i, c := range m.classes {
+		if i == len(block) {
+			break
+		}
+		if !c.contains(block[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *RegexMatcher) Match(address []byte) bool {
+	return m.re.Match(address)
+}
+
+func (m *RegexMatcher) Find(address []byte) ([]byte, []byte) {
+	return []byte(m.expr), m.re.Find(address)
+}
+
+// probability is only known for anchored expressions. It only accounts
+// for the leading characters, so it may be higher than the actual one.
+func (m *RegexMatcher) probability(net Network) (float64, bool) {
+	if len(m.classes) == 0 {
+		return 0, false
+	}
+	sets := make([]*digitSet, len(m.classes))
+	for i, c := range m.classes {
+		sets[i] = &digitSet{}
+		for d, a := range alphabet {
+			sets[i][d] = c.contains(a)
+		}
+	}
+	return positionProbability(net, 0, sets), true
+}
+
+// charClass is a set of ASCII characters
+type charClass [2]uint64
+
+// anyChar contains all ASCII characters
+var anyChar = charClass{^uint64(0), ^uint64(0)}
+
+func (c *charClass) contains(b byte) bool {
+	return b < 128 && c[b/64]&(1<<(b%64)) != 0
+}
+
+// add adds all ASCII characters of the range [lo, hi]
+func (c *charClass) add(lo, hi rune) {
+	for r := lo; r <= hi && r < 128; r++ {
+		if r >= 0 {
+			c[r/64] |= 1 << uint(r%64)
+		}
+	}
+}
+
+// union adds all characters of o
+func (c *charClass) union(o charClass) {
+	c[0] |= o[0]
+	c[1] |= o[1]
+}
+
+// leadingClasses returns the characters allowed at each of the leading
+// positions of all strings re matches, and whether re matches exactly
+// as many characters. The classes may allow more characters than re
+// does, but never less. Zero-width assertions are ignored.
+func leadingClasses(re *syntax.Regexp) ([]charClass, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil, true
+	case syntax.OpLiteral:
+		classes := make([]charClass, len(re.Rune))
+		for i, r := range re.Rune {
+			classes[i].add(r, r)
+			if re.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					classes[i].add(f, f)
+				}
+			}
+		}
+		return classes, true
+	case syntax.OpCharClass:
+		var c charClass
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			c.add(re.Rune[i], re.Rune[i+1])
+		}
+		return []charClass{c}, true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []charClass{anyChar}, true
+	case syntax.OpCapture:
+		return leadingClasses(re.Sub[0])
+	case syntax.OpConcat:
+		var classes []charClass
+		for _, sub := range re.Sub {
+			c, exact := leadingClasses(sub)
+			classes = append(classes, c...)
+			if !exact {
+				return classes, false
+			}
+		}
+		return classes, true
+	case syntax.OpAlternate:
+		var classes []charClass
+		exact := true
+		for i, sub := range re.Sub {
+			c, e := leadingClasses(sub)
+			if i == 0 {
+				classes, exact = c, e
+				continue
+			}
+			if len(c) != len(classes) || !e {
+				exact = false
+			}
+			if len(c) < len(classes) {
+				classes = classes[:len(c)]
+			}
+			for j := range classes {
+				classes[j].union(c[j])
+			}
+		}
+		return classes, exact
+	case syntax.OpPlus:
+		c, _ := leadingClasses(re.Sub[0])
+		return c, false
+	case syntax.OpRepeat:
+		c, exact := leadingClasses(re.Sub[0])
+		if re.Min < 1 {
+			return nil, false
+		}
+		if !exact {
+			return c, false
+		}
+		var classes []charClass
+		for i := 0; i < re.Min; i++ {
+			classes = append(classes, c...)
+		}
+		return classes, re.Min == re.Max
+	}
+	return nil, false
+}
@@ -0,0 +1,105 @@
+package address
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestRegexMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		// classes is the expected number of leading classes
+		classes int
+		// accepted and rejected are first blocks
+		accepted, rejected []string
+	}{
+		{"^4[0-9AB](Team|TEAM)", 6, []string{"48Team", "4ATEAMxxxxx", "4BTeAmxxxxx"}, []string{"4CTeam", "48Tean", "84Team"}},
+		{"^4.(?i)cafe", 6, []string{"4xcafe", "4xCaFexxxxx"}, []string{"4xcafx", "4cafe"}},
+		{"^(abc|xy)z", 2, []string{"ayzzzz", "xbc"}, []string{"bbc"}},
+		{"^4[AB]+c", 2, []string{"4Ac", "4Ax"}, []string{"4cc"}},
+		{"^4a{2}b", 4, []string{"4aab"}, []string{"4abb"}},
+		{"Team", 0, []string{"4xxxxxxxxxx"}, nil},
+		{"^4|Team", 0, []string{"8xxxxxxxxxx"}, nil},
+	} {
+		m, err := NewRegexMatcher(tc.expr, false)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.expr, err.Error())
+		}
+		if got := len(m.classes); got != tc.classes {
+			t.Fatalf("%s: got %d leading classes, expected %d", tc.expr, got, tc.classes)
+		}
+		for _, block := range tc.accepted {
+			if !m.MatchBlock([]byte(block)) {
+				t.Fatalf("%s: expected block %q to be accepted", tc.expr, block)
+			}
+		}
+		for _, block := range tc.rejected {
+			if m.MatchBlock([]byte(block)) {
+				t.Fatalf("%s: expected block %q to be rejected", tc.expr, block)
+			}
+		}
+	}
+
+	if _, err := NewRegexMatcher("^4(", false); err == nil {
+		t.Fatal("expected error for invalid expression")
+	}
+
+	// The pattern of a match is the expression as given
+	m, err := NewRegexMatcher("^4.cafe", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.MatchBlock([]byte("4xCaFexxxxx")) || !m.Match([]byte("4xCaFe")) {
+		t.Fatal("expected expression to match in any casing")
+	}
+	if pattern, match := m.Find([]byte("4xCaFe")); string(pattern) != "^4.cafe" || string(match) != "4xCaFe" {
+		t.Fatalf("got pattern %q and match %q", pattern, match)
+	}
+	if got := m.String(); got != "^4.cafe" {
+		t.Fatalf("got expression %q", got)
+	}
+}
+
+func TestRegexMatcherPrefilter(t *testing.T) {
+	// The prefilter must accept every address the expression matches
+	for _, expr := range []string{"^4[0-9AB]", "^4(6|B)", "^(?i)4b1AHC", "^4B1a.C2k", "^[48][^x]+"} {
+		m, err := NewRegexMatcher(expr, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		re := regexp.MustCompile(expr)
+		for _, fx := range fixtures {
+			if re.MatchString(fx.address) && !m.MatchBlock([]byte(fx.address[:fullEncodedBlockSize])) {
+				t.Fatalf("%s: prefilter rejected matching address %s", expr, fx.address)
+			}
+		}
+	}
+}
+
+func TestNewWithOptionsRegex(t *testing.T) {
+	for _, expr := range []string{"^4[0-9AB]a", "z$"} {
+		m, err := NewRegexMatcher(expr, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := NewWithOptions(context.Background(), SearchOptions{
+			Network: Mainnet,
+			Regex:   m,
+			Workers: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.re.Match(res.Address) {
+			t.Fatalf("%s: address %s does not match", expr, res.Address)
+		}
+		if got := makeAddress(Mainnet.StandardPrefix, res.SpendKeyPair.PublicKey(), res.ViewKeyPair.PublicKey()); string(got) != string(res.Address) {
+			t.Fatalf("%s: got address %s, expected %s", expr, res.Address, got)
+		}
+	}
+
+	if _, err := NewWithOptions(context.Background(), SearchOptions{Network: Mainnet, Workers: 1}); err == nil {
+		t.Fatal("expected error without pattern")
+	}
+}
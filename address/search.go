@@ -0,0 +1,418 @@
+package address
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// searchBatchSize is the number of keys a worker
+// derives at once during a prefix search
+const searchBatchSize = 256
+
+// SearchOptions configures a vanity search.
+// Found addresses match all patterns which are set.
+type SearchOptions struct {
+	Network Network
+	// Prefix is a literal prefix which starts right after
+	// the characters determined by the network byte
+	Prefix []byte
+	// Patterns are literal prefixes like Prefix,
+	// any one of which has to match
+	Patterns [][]byte
+	// Suffix is a literal suffix
+	Suffix []byte
+	// Contains is a literal word which may appear anywhere
+	Contains []byte
+	// IgnoreCase makes Prefix, Patterns, Suffix and
+	// Contains match in any casing
+	IgnoreCase bool
+	// Regex is matched against the full address
+	Regex *RegexMatcher
+	// Matcher is a custom matcher, e.g. built from
+	// MatcherFunc, BlockMatcherFunc, All and Any
+	Matcher Matcher
+	// Count is the number of addresses to find, at least one
+	Count int
+	// EachPattern keeps searching until Count addresses
+	// were found for each of Patterns
+	EachPattern bool
+	// Workers is the number of concurrent workers
+	Workers int
+	// Attempts, if set, counts the attempts of all workers as they
+	// are made. It must only be read with atomic.LoadUint64 while
+	// the search is running.
+	Attempts *uint64
+	// SplitSpendKey and SplitViewKey, if set, are the public keys of a
+	// SplitKey. Addresses are built from the public spend key
+	// SplitSpendKey + bG for offsets b, and the public view key
+	// SplitViewKey. The private key of the spend key pair of a result
+	// is the offset b, its view key pair only holds SplitViewKey.
+	SplitSpendKey, SplitViewKey PublicKey
+	// State, if set, records the positions of the workers as they go.
+	// If it already holds positions, one for each worker, the workers
+	// resume from them, and their attempts are added to Attempts.
+	State *SearchState
+}
+
+// WorkerState is the position of a worker of a search
+type WorkerState struct {
+	// Key is the last private key the worker checked.
+	// It continues with the key right after it.
+	Key [32]byte
+	// Attempts is the number of keys the worker checked
+	Attempts uint64
+}
+
+// SearchState holds the positions of the workers of a search,
+// which can be resumed from them. The positions are private keys
+// and have to be kept as secret as the keys which are found.
+//
+// A worker which found an address stays at the position before it
+// until the address was handed to Advance, so that a saved state
+// agrees with the results which were taken into account.
+type SearchState struct {
+	mu      sync.Mutex
+	workers []WorkerState
+	// pending holds the number of results of each
+	// worker which were not handed to Advance yet
+	pending []int
+}
+
+// NewSearchState returns a state which resumes
+// a search from the given worker positions
+func NewSearchState(workers []WorkerState) *SearchState {
+	return &SearchState{
+		workers: append([]WorkerState(nil), workers...),
+	}
+}
+
+// Workers returns a copy of the current worker positions
+func (s *SearchState) Workers() []WorkerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WorkerState(nil), s.workers...)
+}
+
+// set updates the position of worker wid
+// unless it has results pending
+func (s *SearchState) set(wid int, w WorkerState) {
+	s.mu.Lock()
+	if s.pending[wid] == 0 {
+		s.workers[wid] = w
+	}
+	s.mu.Unlock()
+}
+
+// hold keeps the position of worker wid until
+// the result it is about to send was advanced
+func (s *SearchState) hold(wid int) {
+	s.mu.Lock()
+	s.pending[wid]++
+	s.mu.Unlock()
+}
+
+// Advance moves the worker which found res past it. A caller which
+// saves the state calls it once it took res into account, e.g. after
+// counting it, and before it saves the state again.
+func (s *SearchState) Advance(res *Result) {
+	s.mu.Lock()
+	if w := res.worker; w < len(s.pending) && s.pending[w] > 0 {
+		s.pending[w]--
+		s.workers[w] = res.position
+	}
+	s.mu.Unlock()
+}
+
+// matcher returns the matcher for all patterns of the options
+// and the number of distinct patterns it matches
+func (o *SearchOptions) matcher() (Matcher, int, error) {
+	if err := o.validate(); err != nil {
+		return nil, 0, err
+	}
+	var m []Matcher
+	numPatterns := 1
+	if len(o.Patterns) > 0 {
+		t, err := NewPatternsMatcher(o.Network, o.Patterns, o.IgnoreCase)
+		if err != nil {
+			return nil, 0, err
+		}
+		m = append(m, t)
+		numPatterns = t.(*trieMatcher).size
+	}
+	if len(o.Prefix) > 0 {
+		m = append(m, NewPrefixMatcher(o.Network, o.Prefix, o.IgnoreCase))
+	}
+	if o.Regex != nil {
+		m = append(m, o.Regex)
+	}
+	if len(o.Suffix) > 0 {
+		m = append(m, NewSuffixMatcher(o.Suffix, o.IgnoreCase))
+	}
+	if len(o.Contains) > 0 {
+		m = append(m, NewContainsMatcher(o.Contains, o.IgnoreCase))
+	}
+	if o.Matcher != nil {
+		m = append(m, o.Matcher)
+	}
+	if len(m) == 0 {
+		return nil, 0, fmt.Errorf("no pattern to search for")
+	}
+	return All(m...), numPatterns, nil
+}
+
+// validate returns an error if no address can
+// match one of the literal patterns of the options
+func (o *SearchOptions) validate() error {
+	net := o.Network
+	for _, p := range o.Patterns {
+		if err := validateWord(net, "pattern", p, net.leadingChars(), o.IgnoreCase); err != nil {
+			return err
+		}
+	}
+	if len(o.Prefix) > 0 {
+		if err := ValidatePrefix(net, o.Prefix, o.IgnoreCase); err != nil {
+			return err
+		}
+	}
+	if len(o.Suffix) > 0 {
+		if err := validateWord(net, "suffix", o.Suffix, encodedAddressSize-len(o.Suffix), o.IgnoreCase); err != nil {
+			return err
+		}
+	}
+	if len(o.Contains) > 0 {
+		if err := validateChars("word", o.Contains, o.IgnoreCase); err != nil {
+			return err
+		}
+		if p, _ := NewContainsMatcher(o.Contains, o.IgnoreCase).(estimator).probability(net); p == 0 {
+			return fmt.Errorf("word %q can never appear in a %s address", o.Contains, net.Name)
+		}
+	}
+	if o.Regex != nil {
+		if p, ok := o.Regex.probability(net); ok && p == 0 {
+			return fmt.Errorf("regular expression %s can never match a %s address", o.Regex, net.Name)
+		}
+	}
+	return nil
+}
+
+// Probability returns the probability that a random address matches
+// the patterns of the options. The expected number of attempts to find
+// a match is its inverse. It accounts for the characters which can
+// appear at each position of an address, and for their frequencies.
+func (o SearchOptions) Probability() (float64, error) {
+	m, _, err := o.matcher()
+	if err != nil {
+		return 0, err
+	}
+	p, ok := probability(m, o.Network)
+	if !ok {
+		return 0, fmt.Errorf("the probability of unanchored regular expressions and custom matchers is unknown")
+	}
+	return p, nil
+}
+
+// NewWithOptions searches for an address which matches the patterns
+// of opts. It gives up once ctx is done, in which case it returns
+// ctx.Err(). All workers have exited when it returns.
+func NewWithOptions(ctx context.Context, opts SearchOptions) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opts.Count, opts.EachPattern = 1, false
+	results, err := Search(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := <-results
+	// Wait for all workers to exit
+	for range results {
+	}
+	if !ok {
+		return nil, ctx.Err()
+	}
+	return &res, nil
+}
+
+// Search searches for addresses which match the patterns of opts and
+// sends them on the returned channel as they are found. It stops after
+// opts.Count addresses, or after opts.Count addresses for each pattern
+// if opts.EachPattern is set. Cancel ctx to stop it early. The channel
+// is closed once all workers have exited, and must be drained until then.
+func Search(ctx context.Context, opts SearchOptions) (<-chan Result, error) {
+	if opts.Workers < 1 {
+		return nil, fmt.Errorf("invalid number of workers %d", opts.Workers)
+	}
+	if opts.Count < 0 {
+		return nil, fmt.Errorf("invalid count %d", opts.Count)
+	}
+	if opts.Count == 0 {
+		opts.Count = 1
+	}
+	m, numPatterns, err := opts.matcher()
+	if err != nil {
+		return nil, err
+	}
+	splitBase, err := opts.splitBase()
+	if err != nil {
+		return nil, err
+	}
+	net := opts.Network
+	attempts := opts.Attempts
+	if attempts == nil {
+		attempts = new(uint64)
+	}
+	state := opts.State
+	if state == nil {
+		state = &SearchState{}
+	}
+	// resume holds the positions to resume the workers from
+	resume := state.Workers()
+	state.mu.Lock()
+	state.pending = make([]int, opts.Workers)
+	if len(resume) == 0 {
+		state.workers = make([]WorkerState, opts.Workers)
+	}
+	state.mu.Unlock()
+	switch len(resume) {
+	case 0:
+	case opts.Workers:
+		for _, w := range resume {
+			atomic.AddUint64(attempts, w.Attempts)
+		}
+	default:
+		return nil, fmt.Errorf("got %d worker positions to resume from, expected %d", len(resume), opts.Workers)
+	}
+
+	// Cancelling the search context stops all workers
+	searchCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	found := make(chan Result)
+
+	spawn := func(wid int) error {
+		var start WorkerState
+		if len(resume) > 0 {
+			start = resume[wid]
+		} else {
+			spendKeyPair, err := newSpendKeyPair()
+			if err != nil {
+				return fmt.Errorf("failed to create new spend key pair in worker %d: %q", wid, err)
+			}
+			copy(start.Key[:], spendKeyPair.PrivateKey())
+			state.set(wid, start)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			walker := newKeyWalker(start.Key[:], searchBatchSize)
+			done := start.Attempts
+			c := newCandidate()
+			if splitBase != nil {
+				walker.addBase(splitBase)
+				copy(c.viewPub[:], opts.SplitViewKey)
+			}
+			var block [fullEncodedBlockSize]byte
+			for {
+				select {
+				case <-searchCtx.Done():
+					return
+				default:
+				}
+				walker.next()
+				// counted is the number of attempts of
+				// the batch already added to the counter
+				counted := 0
+				for i := range walker.pubs {
+					// Only derive the view key and encode the full
+					// address if the first block already matches
+					encodeFirstBlock(&block, net.StandardPrefix, walker.pubs[i][:])
+					if !m.MatchBlock(block[:]) {
+						continue
+					}
+					walker.privateKeyTo(&c.spendPriv, i)
+					c.spendPub = walker.pubs[i]
+					if splitBase == nil {
+						c.deriveViewKey()
+					}
+					c.encodeAddress(net.StandardPrefix)
+					if !m.Match(c.address[:]) {
+						continue
+					}
+					spendKeyPair, viewKeyPair := c.keyPairs()
+					if splitBase != nil {
+						// Only the searched public view key is known
+						viewKeyPair.priv = nil
+					}
+					address := append([]byte(nil), c.address[:]...)
+					pattern, match := find(m, address)
+					res := Result{
+						ViewKeyPair:  viewKeyPair,
+						SpendKeyPair: spendKeyPair,
+						Address:      address,
+						Pattern:      pattern,
+						Match:        match,
+						Attempts:     atomic.AddUint64(attempts, uint64(i+1-counted)),
+						worker:       wid,
+					}
+					counted = i + 1
+					// A resumed search continues after the match
+					res.position = WorkerState{Key: c.spendPriv, Attempts: done + uint64(counted)}
+					state.hold(wid)
+					select {
+					case found <- res:
+					case <-searchCtx.Done():
+						return
+					}
+				}
+				atomic.AddUint64(attempts, uint64(searchBatchSize-counted))
+				done += searchBatchSize
+				state.set(wid, WorkerState{Key: walker.scalar, Attempts: done})
+			}
+		}()
+		return nil
+	}
+
+	for i := 0; i < opts.Workers && searchCtx.Err() == nil; i++ {
+		if err := spawn(i); err != nil {
+			log.Printf("%q, retrying", err)
+			i-- // Retry
+		}
+	}
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer cancel()
+
+		remaining := opts.Count
+		if opts.EachPattern {
+			remaining *= numPatterns
+		}
+		// seen holds the number of addresses found for each pattern
+		seen := make(map[string]int)
+		for remaining > 0 {
+			var res Result
+			select {
+			case res = <-found:
+			case <-searchCtx.Done():
+				return
+			}
+			if opts.EachPattern {
+				if seen[string(res.Pattern)] == opts.Count {
+					state.Advance(&res)
+					continue
+				}
+				seen[string(res.Pattern)]++
+			}
+			select {
+			case results <- res:
+				remaining--
+			case <-searchCtx.Done():
+				return
+			}
+		}
+	}()
+	return results, nil
+}
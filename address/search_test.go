@@ -0,0 +1,248 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTrieMatcher(t *testing.T) {
+	m := newTrieMatcher(2, false)
+	for _, p := range []string{"ab", "abcd", "x", "ab"} {
+		m.add([]byte(p))
+	}
+	if m.size != 3 {
+		t.Fatalf("got %d patterns, expected 3", m.size)
+	}
+	for _, tc := range []struct {
+		address, pattern string
+	}{
+		{"44abxxx", "ab"},
+		{"44abcdx", "abcd"},
+		{"44abcxx", "ab"},
+		{"44xxxxx", "x"},
+		{"44Abxxx", ""},
+		{"44cxxxx", ""},
+	} {
+		pattern, match := m.Find([]byte(tc.address))
+		if string(pattern) != tc.pattern {
+			t.Fatalf("%s: got pattern %q, expected %q", tc.address, pattern, tc.pattern)
+		}
+		if got := m.Match([]byte(tc.address)); got != (tc.pattern != "") {
+			t.Fatalf("%s: got match %t", tc.address, got)
+		}
+		if tc.pattern != "" && string(match) != tc.pattern {
+			t.Fatalf("%s: got match %q, expected %q", tc.address, match, tc.pattern)
+		}
+	}
+	// The block ends before the trie does
+	if !m.MatchBlock([]byte("44abc")) {
+		t.Fatal("expected partial block to be accepted")
+	}
+	if !m.MatchBlock([]byte("44abx")) || m.MatchBlock([]byte("44yyy")) {
+		t.Fatal("got incorrect block match")
+	}
+
+	m = newTrieMatcher(2, true)
+	m.add([]byte("Cafe"))
+	m.add([]byte("cAFE"))
+	if m.size != 1 {
+		t.Fatalf("got %d patterns, expected 1", m.size)
+	}
+	if pattern, match := m.Find([]byte("44CAfExx")); string(pattern) != "Cafe" || string(match) != "CAfE" {
+		t.Fatalf("got pattern %q and match %q", pattern, match)
+	}
+}
+
+func TestSearchEachPattern(t *testing.T) {
+	patterns := [][]byte{[]byte("a"), []byte("b"), []byte("C")}
+	results, err := Search(context.Background(), SearchOptions{
+		Network:     Mainnet,
+		Patterns:    patterns,
+		Count:       2,
+		EachPattern: true,
+		Workers:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]int)
+	for res := range results {
+		if seen[string(res.Pattern)]++; seen[string(res.Pattern)] > 2 {
+			t.Fatalf("got pattern %q more than twice", res.Pattern)
+		}
+		if !bytes.HasPrefix(res.Address[2:], res.Pattern) {
+			t.Fatalf("address %s does not match pattern %q", res.Address, res.Pattern)
+		}
+		if res.Attempts == 0 {
+			t.Fatal("got no attempts")
+		}
+	}
+	if len(seen) != len(patterns) {
+		t.Fatalf("got %d patterns, expected %d", len(seen), len(patterns))
+	}
+	for p, n := range seen {
+		if n != 2 {
+			t.Fatalf("got %d addresses for pattern %q, expected 2", n, p)
+		}
+	}
+}
+
+func TestSearchCount(t *testing.T) {
+	const count = 5
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := Search(ctx, SearchOptions{
+		Network: Mainnet,
+		Prefix:  []byte("a"),
+		Count:   count,
+		Workers: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for res := range results {
+		if seen[string(res.Address)] {
+			t.Fatalf("got address %s twice", res.Address)
+		}
+		seen[string(res.Address)] = true
+		if !bytes.HasPrefix(res.Address[2:], []byte("a")) {
+			t.Fatalf("address %s does not have prefix a", res.Address)
+		}
+	}
+	if len(seen) != count {
+		t.Fatalf("got %d addresses, expected %d", len(seen), count)
+	}
+
+	// Stop early
+	if results, err = Search(ctx, SearchOptions{
+		Network: Mainnet,
+		Prefix:  []byte("a"),
+		Count:   1 << 20,
+		Workers: 3,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-results
+	cancel()
+	for range results {
+	}
+
+	if _, err := Search(context.Background(), SearchOptions{Network: Mainnet, Prefix: []byte("a"), Count: -1, Workers: 1}); err == nil {
+		t.Fatal("expected error for negative count")
+	}
+}
+
+func TestMatcherCombinators(t *testing.T) {
+	a := NewPrefixMatcher(Mainnet, []byte("ab"), false)
+	b := NewSuffixMatcher([]byte("yz"), false)
+	for _, tc := range []struct {
+		address  string
+		all, any bool
+	}{
+		{"44abxxyz", true, true},
+		{"44abxxxx", false, true},
+		{"44xxxxyz", false, true},
+		{"44xxxxxx", false, false},
+	} {
+		if got := All(a, b).Match([]byte(tc.address)); got != tc.all {
+			t.Fatalf("%s: got all match %t", tc.address, got)
+		}
+		if got := Any(a, b).Match([]byte(tc.address)); got != tc.any {
+			t.Fatalf("%s: got any match %t", tc.address, got)
+		}
+	}
+	if pattern, _ := find(Any(a, b), []byte("44xxxxyz")); string(pattern) != "yz" {
+		t.Fatalf("got pattern %q, expected yz", pattern)
+	}
+	// A suffix can not reject any block
+	if !Any(a, b).MatchBlock([]byte("44xxxxxxxxx")) || All(a, b).MatchBlock([]byte("44xxxxxxxxx")) {
+		t.Fatal("got incorrect block match")
+	}
+	if _, ok := probability(All(a, MatcherFunc(func([]byte) bool { return true })), Mainnet); ok {
+		t.Fatal("expected probability of custom matcher to be unknown")
+	}
+}
+
+func TestSearchCustomMatcher(t *testing.T) {
+	// No ambiguous-looking characters in the first 8
+	unambiguous := BlockMatcherFunc(func(block []byte) bool {
+		return !bytes.ContainsAny(block[:8], "1Oo0Il")
+	})
+	res, err := NewWithOptions(context.Background(), SearchOptions{
+		Network: Mainnet,
+		Matcher: All(unambiguous, MatcherFunc(func(address []byte) bool {
+			return address[len(address)-1] == 'x'
+		})),
+		Workers: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.ContainsAny(res.Address[:8], "1Oo0Il") || res.Address[len(res.Address)-1] != 'x' {
+		t.Fatalf("address %s does not match", res.Address)
+	}
+	if _, err := (SearchOptions{Network: Mainnet, Matcher: unambiguous}).Probability(); err == nil {
+		t.Fatal("expected probability of custom matcher to be unknown")
+	}
+}
+
+func TestSearchResume(t *testing.T) {
+	var start WorkerState
+	start.Key[0] = 5
+	search := func(state *SearchState, count int) []Result {
+		results, err := Search(context.Background(), SearchOptions{
+			Network: Mainnet,
+			Prefix:  []byte("a"),
+			Count:   count,
+			Workers: 1,
+			State:   state,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []Result
+		for res := range results {
+			found = append(found, res)
+		}
+		return found
+	}
+
+	// A search which is resumed after the first match finds
+	// the same second match as an uninterrupted search
+	want := search(NewSearchState([]WorkerState{start}), 2)
+	state := NewSearchState([]WorkerState{start})
+	first := search(state, 1)
+	if !bytes.Equal(first[0].Address, want[0].Address) {
+		t.Fatalf("got address %s, expected %s", first[0].Address, want[0].Address)
+	}
+	// The worker stays before the match until it was advanced
+	if ws := state.Workers(); ws[0] != start {
+		t.Fatalf("got worker state %+v before advancing, expected %+v", ws[0], start)
+	}
+	state.Advance(&first[0])
+	ws := state.Workers()
+	if ws[0].Attempts != first[0].Attempts {
+		t.Fatalf("got %d worker attempts, expected %d", ws[0].Attempts, first[0].Attempts)
+	}
+	// The worker stopped at the key it found
+	var key [32]byte
+	copy(key[:], first[0].SpendKeyPair.PrivateKey())
+	if ws[0].Key != key {
+		t.Fatalf("got worker key %x, expected %x", ws[0].Key, key)
+	}
+	second := search(state, 1)
+	if !bytes.Equal(second[0].Address, want[1].Address) || second[0].Attempts != want[1].Attempts {
+		t.Fatalf("got address %s after %d attempts, expected %s after %d", second[0].Address, second[0].Attempts, want[1].Address, want[1].Attempts)
+	}
+
+	if _, err := Search(context.Background(), SearchOptions{
+		Network: Mainnet,
+		Prefix:  []byte("a"),
+		Workers: 2,
+		State:   NewSearchState([]WorkerState{start}),
+	}); err == nil {
+		t.Fatal("expected mismatching number of workers to be rejected")
+	}
+}
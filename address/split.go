@@ -0,0 +1,114 @@
+package address
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+// SplitKey is the secret half of a split-key vanity search. Only its
+// public keys are handed to the searcher, which looks for an offset b
+// such that the address of the public spend key A + bG and the public
+// view key matches. The private spend key of the address is a + b.
+//
+// The view key is random rather than derived from the private spend
+// key, since the searcher does not know the private spend key. The
+// wallet can therefore not be restored from a mnemonic seed.
+type SplitKey struct {
+	Spend, View *KeyPair
+}
+
+// newRandomKeyPair generates a new key pair with a random private key
+func newRandomKeyPair() (*KeyPair, error) {
+	var seed [64]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %s", err.Error())
+	}
+	var priv [32]byte
+	edwards25519.ScReduce(&priv, &seed)
+	return &KeyPair{priv[:], private2Public(priv[:])}, nil
+}
+
+// NewSplitKey generates a new random split key
+func NewSplitKey() (*SplitKey, error) {
+	spend, err := newRandomKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spend key pair: %s", err.Error())
+	}
+	view, err := newRandomKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create view key pair: %s", err.Error())
+	}
+	return &SplitKey{spend, view}, nil
+}
+
+// validPrivateKey returns an error if priv is not a canonical, reduced,
+// non-zero scalar. name names the key in errors.
+func validPrivateKey(name string, priv PrivateKey) error {
+	if len(priv) != 32 {
+		return fmt.Errorf("invalid %s length %d, expected 32", name, len(priv))
+	}
+	if !isReduced(priv) {
+		return fmt.Errorf("%s is not a reduced scalar", name)
+	}
+	if bytes.Equal(priv, make([]byte, 32)) {
+		return fmt.Errorf("%s is zero", name)
+	}
+	return nil
+}
+
+// SplitKeyFromPrivateKeys returns the split key of existing private keys
+func SplitKeyFromPrivateKeys(spend, view PrivateKey) (*SplitKey, error) {
+	if err := validPrivateKey("private spend key", spend); err != nil {
+		return nil, err
+	}
+	if err := validPrivateKey("private view key", view); err != nil {
+		return nil, err
+	}
+	spend, view = append(PrivateKey(nil), spend...), append(PrivateKey(nil), view...)
+	return &SplitKey{
+		Spend: &KeyPair{spend, private2Public(spend)},
+		View:  &KeyPair{view, private2Public(view)},
+	}, nil
+}
+
+// Combine returns the spend and view key pairs of the wallet
+// which a split-key search found with the given offset
+func (k *SplitKey) Combine(offset PrivateKey) (*KeyPair, *KeyPair, error) {
+	if len(offset) != 32 || !isReduced(offset) {
+		return nil, nil, fmt.Errorf("offset is not a reduced scalar")
+	}
+	// spend = 1 * a + b
+	var one, a, b, spend [32]byte
+	one[0] = 1
+	copy(a[:], k.Spend.priv)
+	copy(b[:], offset)
+	edwards25519.ScMulAdd(&spend, &one, &a, &b)
+	if err := validPrivateKey("private spend key", spend[:]); err != nil {
+		return nil, nil, err
+	}
+	return &KeyPair{spend[:], private2Public(spend[:])}, k.View, nil
+}
+
+// splitBase returns the point of the public spend key
+// of a split-key search, or nil if there is none
+func (o *SearchOptions) splitBase() (*edwards25519.ExtendedGroupElement, error) {
+	if o.SplitSpendKey == nil && o.SplitViewKey == nil {
+		return nil, nil
+	}
+	if !isValidPublicKey(o.SplitSpendKey) {
+		return nil, fmt.Errorf("invalid split public spend key")
+	}
+	if !isValidPublicKey(o.SplitViewKey) {
+		return nil, fmt.Errorf("invalid split public view key")
+	}
+	var (
+		p   edwards25519.ExtendedGroupElement
+		pub [32]byte
+	)
+	copy(pub[:], o.SplitSpendKey)
+	p.FromBytes(&pub)
+	return &p, nil
+}
@@ -0,0 +1,62 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSplitKey(t *testing.T) {
+	k, err := NewSplitKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := NewWithOptions(context.Background(), SearchOptions{
+		Network:       Stagenet,
+		Prefix:        []byte("a"),
+		SplitSpendKey: k.Spend.PublicKey(),
+		SplitViewKey:  k.View.PublicKey(),
+		Workers:       2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ViewKeyPair.PrivateKey() != nil || !bytes.Equal(res.ViewKeyPair.PublicKey(), k.View.PublicKey()) {
+		t.Fatal("got incorrect view key pair")
+	}
+
+	// Combine the split key from its private keys, like the owner would
+	k, err = SplitKeyFromPrivateKeys(k.Spend.PrivateKey(), k.View.PrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendKeyPair, viewKeyPair, err := k.Combine(res.SpendKeyPair.PrivateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spendKeyPair.PublicKey(), res.SpendKeyPair.PublicKey()) {
+		t.Fatalf("got public spend key %x, expected %x", spendKeyPair.PublicKey(), res.SpendKeyPair.PublicKey())
+	}
+	if addr := makeAddress(Stagenet.StandardPrefix, spendKeyPair.PublicKey(), viewKeyPair.PublicKey()); !bytes.Equal(addr, res.Address) {
+		t.Fatalf("got address %s, expected %s", addr, res.Address)
+	}
+	// The view key is not derived from the spend key
+	if bytes.Equal(makeViewKeyPair(spendKeyPair.PrivateKey()).PrivateKey(), viewKeyPair.PrivateKey()) {
+		t.Fatal("expected random view key")
+	}
+
+	if _, _, err := k.Combine(bytes.Repeat([]byte{0xff}, 32)); err == nil {
+		t.Fatal("expected unreduced offset to be rejected")
+	}
+	if _, err := SplitKeyFromPrivateKeys(make([]byte, 32), k.View.PrivateKey()); err == nil {
+		t.Fatal("expected zero private spend key to be rejected")
+	}
+	if _, err := Search(context.Background(), SearchOptions{
+		Network:       Mainnet,
+		Prefix:        []byte("a"),
+		SplitSpendKey: k.Spend.PublicKey(),
+		Workers:       1,
+	}); err == nil {
+		t.Fatal("expected missing public view key to be rejected")
+	}
+}
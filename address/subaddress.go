@@ -0,0 +1,106 @@
+package address
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/agl/ed25519/edwards25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// subaddressSecret returns the secret m of subaddress (major, minor):
+// m = Hs("SubAddr\x00" | privateViewKey | major | minor)
+func subaddressSecret(viewPriv PrivateKey, major, minor uint32) [32]byte {
+	var index [8]byte
+	binary.LittleEndian.PutUint32(index[:4], major)
+	binary.LittleEndian.PutUint32(index[4:], minor)
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range [][]byte{[]byte("SubAddr\x00"), viewPriv, index[:]} {
+		if _, err := h.Write(b); err != nil {
+			panic(err)
+		}
+	}
+	var m [32]byte
+	copy(m[:], reduce(h.Sum(nil)))
+	return m
+}
+
+// DeriveSubaddress returns the public spend and view keys of subaddress
+// (major, minor) of the wallet with the given private view key and public
+// spend key. Subaddress (0, 0) is the wallet's primary address.
+func DeriveSubaddress(viewPriv PrivateKey, spendPub PublicKey, major, minor uint32) (PublicKey, PublicKey, error) {
+	if !isReduced(viewPriv) {
+		return nil, nil, fmt.Errorf("invalid private view key")
+	}
+	var B edwards25519.ExtendedGroupElement
+	var spend [32]byte
+	copy(spend[:], spendPub)
+	if len(spendPub) != 32 || !B.FromBytes(&spend) {
+		return nil, nil, fmt.Errorf("invalid public spend key")
+	}
+	if major == 0 && minor == 0 {
+		return spendPub, private2Public(viewPriv), nil
+	}
+
+	// D = B + m*G
+	var one, zero, view [32]byte
+	one[0] = 1
+	m := subaddressSecret(viewPriv, major, minor)
+	var P edwards25519.ProjectiveGroupElement
+	edwards25519.GeDoubleScalarMultVartime(&P, &one, &B, &m)
+	var pubSpend [32]byte
+	P.ToBytes(&pubSpend)
+
+	// C = a*D
+	var D edwards25519.ExtendedGroupElement
+	if !D.FromBytes(&pubSpend) {
+		panic("derived invalid public spend key")
+	}
+	copy(view[:], viewPriv)
+	edwards25519.GeDoubleScalarMultVartime(&P, &view, &D, &zero)
+	var pubView [32]byte
+	P.ToBytes(&pubView)
+
+	return pubSpend[:], pubView[:], nil
+}
+
+// DeriveSubaddressSpendKey returns the private spend key of subaddress
+// (major, minor) of the wallet with the given private view and spend keys
+func DeriveSubaddressSpendKey(viewPriv, spendPriv PrivateKey, major, minor uint32) (PrivateKey, error) {
+	if !isReduced(viewPriv) {
+		return nil, fmt.Errorf("invalid private view key")
+	}
+	if !isReduced(spendPriv) {
+		return nil, fmt.Errorf("invalid private spend key")
+	}
+	if major == 0 && minor == 0 {
+		return spendPriv, nil
+	}
+
+	// b + m
+	var one, spend, priv [32]byte
+	one[0] = 1
+	copy(spend[:], spendPriv)
+	m := subaddressSecret(viewPriv, major, minor)
+	edwards25519.ScMulAdd(&priv, &one, &spend, &m)
+	return priv[:], nil
+}
+
+// NewSubaddress returns subaddress (major, minor) of the wallet
+// with the given private view key and public spend key
+func NewSubaddress(net Network, viewPriv PrivateKey, spendPub PublicKey, major, minor uint32) (*Address, error) {
+	pubSpend, pubView, err := DeriveSubaddress(viewPriv, spendPub, major, minor)
+	if err != nil {
+		return nil, err
+	}
+	typ := Subaddress
+	if major == 0 && minor == 0 {
+		typ = Standard
+	}
+	return &Address{
+		Network:  net,
+		Type:     typ,
+		SpendKey: pubSpend,
+		ViewKey:  pubView,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+func TestDeriveSubaddress(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		privSpend, pubSpend := h2b(fx.privSpendHex), h2b(fx.pubSpendHex)
+		privView := h2b(fx.privViewHex)
+
+		// Subaddress (0, 0) is the primary address
+		addr, err := NewSubaddress(Mainnet, privView, pubSpend, 0, 0)
+		if err != nil {
+			return err
+		}
+		if addr.String() != fx.address {
+			return fmt.Errorf("got incorrect primary address: %s", addr)
+		}
+
+		seen := map[string]bool{fx.address: true}
+		for _, index := range [][2]uint32{{0, 1}, {0, 2}, {1, 0}, {1, 1}, {42, 1337}} {
+			d, c, err := DeriveSubaddress(privView, pubSpend, index[0], index[1])
+			if err != nil {
+				return err
+			}
+			priv, err := DeriveSubaddressSpendKey(privView, privSpend, index[0], index[1])
+			if err != nil {
+				return err
+			}
+			// D = (b + m)*G
+			if got := private2Public(priv); !bytes.Equal(got, d) {
+				return fmt.Errorf("%v: got incorrect public spend key: %s", index, b2h(got))
+			}
+			// C = a*D = (a * (b + m))*G
+			var a, bm, zero, av [32]byte
+			copy(a[:], privView)
+			copy(bm[:], priv)
+			edwards25519.ScMulAdd(&av, &a, &bm, &zero)
+			if got := private2Public(av[:]); !bytes.Equal(got, c) {
+				return fmt.Errorf("%v: got incorrect public view key: %s", index, b2h(got))
+			}
+
+			addr, err := NewSubaddress(Mainnet, privView, pubSpend, index[0], index[1])
+			if err != nil {
+				return err
+			}
+			s := addr.String()
+			if s[0] != '8' || seen[s] {
+				return fmt.Errorf("%v: got incorrect subaddress: %s", index, s)
+			}
+			seen[s] = true
+			if parsed, err := Parse(s); err != nil || parsed.Type != Subaddress {
+				return fmt.Errorf("%v: got invalid subaddress: %v", index, err)
+			}
+		}
+		return nil
+	}, t)
+}
+
+func TestDeriveSubaddressInvalid(t *testing.T) {
+	fx := fixtures[0]
+	unreduced := bytes.Repeat([]byte{0xff}, 32)
+	if _, _, err := DeriveSubaddress(unreduced, h2b(fx.pubSpendHex), 0, 1); err == nil {
+		t.Fatal("expected error for invalid private view key")
+	}
+	if _, _, err := DeriveSubaddress(h2b(fx.privViewHex), h2b(fx.pubSpendHex)[:31], 0, 1); err == nil {
+		t.Fatal("expected error for invalid public spend key")
+	}
+	if _, err := DeriveSubaddressSpendKey(h2b(fx.privViewHex), unreduced, 0, 1); err == nil {
+		t.Fatal("expected error for invalid private spend key")
+	}
+}
+
+func TestSubaddressKnownAnswer(t *testing.T) {
+	// The wallet of Monero's functional tests, whose subaddresses
+	// are listed in tests/functional_tests/wallet_address.py
+	const (
+		privView = "49774391fa5e8d249fc2c5b45dadef13534bf2483dede880dac88f061e809100"
+		pubSpend = "1b3bd040020d3712ab84992b773d0a965134eb2df0392fb84af95de8a17be2ab"
+	)
+	for _, tc := range []struct {
+		major, minor uint32
+		address      string
+	}{
+		{0, 0, "42ey1afDFnn4886T7196doS9GPMzexD9gXpsZJDwVjeRVdFCSoHnv7KPbBeGpzJBzHRCAs9UxqeoyFQMYbqSWYTfJJQAWDm"},
+		{0, 1, "84QRUYawRNrU3NN1VpFRndSukeyEb3Xpv8qZjjsoJZnTYpDYceuUTpog13D7qPxpviS7J29bSgSkR11hFFoXWk2yNdsR9WF"},
+	} {
+		addr, err := NewSubaddress(Mainnet, h2b(privView), h2b(pubSpend), tc.major, tc.minor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := addr.String(); got != tc.address {
+			t.Errorf("%d/%d: got subaddress %s, expected %s", tc.major, tc.minor, got, tc.address)
+		}
+	}
+}
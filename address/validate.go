@@ -0,0 +1,55 @@
+package address
+
+import (
+	"fmt"
+)
+
+// ValidatePrefix returns an error if no standard address of the
+// network can have the prefix, which starts right after the characters
+// determined by the network byte
+func ValidatePrefix(net Network, prefix []byte, ignoreCase bool) error {
+	return validateWord(net, "prefix", prefix, net.leadingChars(), ignoreCase)
+}
+
+// validateWord returns an error if no standard address of the network
+// can have word at position pos. kind names the word in errors.
+func validateWord(net Network, kind string, word []byte, pos int, ignoreCase bool) error {
+	if len(word) == 0 {
+		return fmt.Errorf("empty %s", kind)
+	}
+	if pos < 0 || pos+len(word) > encodedAddressSize {
+		return fmt.Errorf("%s %q is longer than an address", kind, word)
+	}
+	sets := make([]*digitSet, len(word))
+	for i, c := range word {
+		sets[i] = charDigits(c, ignoreCase)
+		// Positions are 1-based in errors
+		if sets[i].size() == 0 {
+			return fmt.Errorf("character %q at position %d is not in Monero base58", c, pos+i+1)
+		}
+		if positionProbability(net, pos+i, sets[i:i+1]) == 0 {
+			return fmt.Errorf("character %q at position %d can never appear in a %s address", c, pos+i+1, net.Name)
+		}
+	}
+	if positionProbability(net, pos, sets) == 0 {
+		return fmt.Errorf("%s %q can never appear in a %s address", kind, word, net.Name)
+	}
+	return nil
+}
+
+// validateChars returns an error if a character of
+// word is not in the alphabet. kind names the word in errors.
+func validateChars(kind string, word []byte, ignoreCase bool) error {
+	if len(word) == 0 {
+		return fmt.Errorf("empty %s", kind)
+	}
+	if len(word) > encodedAddressSize {
+		return fmt.Errorf("%s %q is longer than an address", kind, word)
+	}
+	for i, c := range word {
+		if charDigits(c, ignoreCase).size() == 0 {
+			return fmt.Errorf("character %q at position %d of the %s is not in Monero base58", c, i+1, kind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// WatchOnly contains everything needed to watch a wallet for incoming
+// transactions, but never its private spend key
+type WatchOnly struct {
+	Address *Address
+	// SpendKey is the public spend key of the wallet
+	SpendKey PublicKey
+	// ViewKey is the private view key of the wallet
+	ViewKey PrivateKey
+}
+
+// NewWatchOnly returns the watch-only bundle of a standard address. The
+// private view key must belong to the public view key of the address.
+func NewWatchOnly(viewPriv PrivateKey, addr *Address) (*WatchOnly, error) {
+	if addr.Type != Standard {
+		return nil, fmt.Errorf("expected a standard address, got a %s address", addr.Type)
+	}
+	if !isReduced(viewPriv) {
+		return nil, fmt.Errorf("invalid private view key")
+	}
+	if !bytes.Equal(private2Public(viewPriv), addr.ViewKey) {
+		return nil, fmt.Errorf("private view key does not match the public view key of the address")
+	}
+	return &WatchOnly{
+		Address:  addr,
+		SpendKey: addr.SpendKey,
+		ViewKey:  viewPriv,
+	}, nil
+}
+
+// NewWatchOnlyFromSpendKey returns the watch-only bundle
+// of the wallet with the given private view key and public
+// spend key on the given network
+func NewWatchOnlyFromSpendKey(net Network, viewPriv PrivateKey, spendPub PublicKey) (*WatchOnly, error) {
+	if !isValidPublicKey(spendPub) {
+		return nil, fmt.Errorf("invalid public spend key")
+	}
+	if !isReduced(viewPriv) {
+		return nil, fmt.Errorf("invalid private view key")
+	}
+	return NewWatchOnly(viewPriv, &Address{
+		Network:  net,
+		Type:     Standard,
+		SpendKey: spendPub,
+		ViewKey:  private2Public(viewPriv),
+	})
+}
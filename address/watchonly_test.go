@@ -0,0 +1,47 @@
+package address
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewWatchOnly(t *testing.T) {
+	foreachFixture(func(fx fixture) error {
+		addr, err := Parse(fx.address)
+		if err != nil {
+			return err
+		}
+		for _, newWatchOnly := range []func() (*WatchOnly, error){
+			func() (*WatchOnly, error) {
+				return NewWatchOnly(h2b(fx.privViewHex), addr)
+			},
+			func() (*WatchOnly, error) {
+				return NewWatchOnlyFromSpendKey(Mainnet, h2b(fx.privViewHex), h2b(fx.pubSpendHex))
+			},
+		} {
+			wo, err := newWatchOnly()
+			if err != nil {
+				return err
+			}
+			if got := wo.Address.String(); got != fx.address {
+				return fmt.Errorf("got incorrect address: %s", got)
+			}
+			if got := b2h(wo.SpendKey); got != fx.pubSpendHex {
+				return fmt.Errorf("got incorrect public spend key: %s", got)
+			}
+			if got := b2h(wo.ViewKey); got != fx.privViewHex {
+				return fmt.Errorf("got incorrect private view key: %s", got)
+			}
+		}
+		return nil
+	}, t)
+
+	// The view key of another wallet must be rejected
+	addr, err := Parse(fixtures[0].address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWatchOnly(h2b(fixtures[1].privViewHex), addr); err == nil {
+		t.Fatal("expected error for mismatching private view key")
+	}
+}
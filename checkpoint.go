@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leonklingele/malvarmo/address"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// checkpointVersion is the version of the checkpoint file format
+	checkpointVersion = 1
+	// checkpointIterations is the number of PBKDF2 iterations
+	// to derive the checkpoint key from the passphrase
+	checkpointIterations = 600000
+	// passphraseEnv is the environment variable
+	// which holds the checkpoint passphrase
+	passphraseEnv = "MALVARMO_CHECKPOINT_PASSPHRASE"
+)
+
+// checkpointParams are the flags which determine a search. A search
+// can only be resumed with the flags it was started with.
+var checkpointParams = []string{
+	"network", "prefix", "regex", "patterns", "suffix", "contains",
+	"ignore-case", "count", "each-pattern", "workers",
+}
+
+// checkpointFile is the file format of a checkpoint. The checkpoint
+// is encrypted with AES-256-GCM, its key is derived from a passphrase
+// with PBKDF2-HMAC-SHA256.
+type checkpointFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// checkpoint is the state of a search
+type checkpoint struct {
+	// Params holds the values of the checkpointParams flags
+	Params map[string]string `json:"params"`
+	// Patterns holds the patterns read from the -patterns file
+	Patterns []string `json:"patterns,omitempty"`
+	// Found is the number of addresses found so far
+	Found   int                `json:"found"`
+	Workers []checkpointWorker `json:"workers"`
+}
+
+// checkpointWorker is the position of a worker
+type checkpointWorker struct {
+	Key      string `json:"key"`
+	Attempts uint64 `json:"attempts"`
+}
+
+// checkpointFlags configure the checkpoints of a search
+type checkpointFlags struct {
+	path, resume *string
+	interval     *time.Duration
+}
+
+// registerCheckpointFlags registers the flags to
+// configure the checkpoints of a search
+func registerCheckpointFlags(fs *flag.FlagSet) *checkpointFlags {
+	return &checkpointFlags{
+		path:     fs.String("checkpoint", "", "optional, the file to periodically save the state of the search to, encrypted with the passphrase in $"+passphraseEnv+" or piped to stdin"),
+		resume:   fs.String("resume", "", "optional, the checkpoint file to resume a search from, which is updated as the search goes on"),
+		interval: fs.Duration("checkpoint-interval", time.Minute, "optional, the interval in which the checkpoint is saved"),
+	}
+}
+
+// checkpointer saves the state of a search to a file
+type checkpointer struct {
+	path       string
+	interval   time.Duration
+	salt       []byte
+	iterations int
+	key        []byte
+	cp         *checkpoint
+}
+
+// readPassphrase returns the checkpoint passphrase from the environment,
+// or reads it from stdin if stdin is not a terminal. A terminal would
+// echo the passphrase, so it has to be set in the environment then.
+func readPassphrase() ([]byte, error) {
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+	if isTerminal(os.Stdin) {
+		return nil, fmt.Errorf("the checkpoint passphrase must be set in $%s, reading it from a terminal would show it", passphraseEnv)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("failed to read checkpoint passphrase: %s", err.Error())
+	}
+	p := strings.TrimRight(line, "\r\n")
+	if p == "" {
+		return nil, fmt.Errorf("empty checkpoint passphrase")
+	}
+	return []byte(p), nil
+}
+
+// deriveKey derives the checkpoint key from the
+// passphrase and the salt with PBKDF2-HMAC-SHA256
+func deriveKey(passphrase, salt []byte, iterations int) []byte {
+	return pbkdf2.Key(passphrase, salt, iterations, 32, sha256.New)
+}
+
+// newAEAD returns the cipher for a checkpoint key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %s", err.Error())
+	}
+	return cipher.NewGCM(block)
+}
+
+// newCheckpointer returns a checkpointer which saves
+// to path with a key derived from the passphrase
+func newCheckpointer(path string, interval time.Duration, passphrase []byte) (*checkpointer, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid checkpoint interval %s", interval)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to create salt: %s", err.Error())
+	}
+	return &checkpointer{
+		path:       path,
+		interval:   interval,
+		salt:       salt,
+		iterations: checkpointIterations,
+		key:        deriveKey(passphrase, salt, checkpointIterations),
+	}, nil
+}
+
+// loadCheckpoint reads and decrypts the checkpoint at path. The
+// returned checkpointer saves to the same file with the same key.
+func loadCheckpoint(path string, interval time.Duration, passphrase []byte) (*checkpointer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %s", err.Error())
+	}
+	var f checkpointFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %s", err.Error())
+	}
+	if f.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", f.Version)
+	}
+	if f.Iterations < 1 {
+		return nil, fmt.Errorf("invalid number of checkpoint iterations %d", f.Iterations)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid checkpoint interval %s", interval)
+	}
+	c := &checkpointer{
+		path:       path,
+		interval:   interval,
+		salt:       f.Salt,
+		iterations: f.Iterations,
+		key:        deriveKey(passphrase, f.Salt, f.Iterations),
+	}
+	aead, err := newAEAD(c.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid checkpoint nonce")
+	}
+	plaintext, err := aead.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt checkpoint, wrong passphrase?")
+	}
+	c.cp = &checkpoint{}
+	if err := json.Unmarshal(plaintext, c.cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %s", err.Error())
+	}
+	return c, nil
+}
+
+// save encrypts the checkpoint and replaces the checkpoint
+// file with it. state holds the current worker positions.
+func (c *checkpointer) save(state *address.SearchState) error {
+	var workers []checkpointWorker
+	for _, w := range state.Workers() {
+		workers = append(workers, checkpointWorker{
+			Key:      hex.EncodeToString(w.Key[:]),
+			Attempts: w.Attempts,
+		})
+	}
+	c.cp.Workers = workers
+	plaintext, err := json.Marshal(c.cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %s", err.Error())
+	}
+	aead, err := newAEAD(c.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to create nonce: %s", err.Error())
+	}
+	data, err := json.Marshal(&checkpointFile{
+		Version:    checkpointVersion,
+		Salt:       c.salt,
+		Iterations: c.iterations,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %s", err.Error())
+	}
+
+	// Replace the file atomically, so a crash leaves the old checkpoint
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %s", err.Error())
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %s", err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %s", err.Error())
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %s", err.Error())
+	}
+	return nil
+}
+
+// state returns the worker positions of the checkpoint
+func (cp *checkpoint) state() (*address.SearchState, error) {
+	var workers []address.WorkerState
+	for _, w := range cp.Workers {
+		key, err := hex.DecodeString(w.Key)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid worker key in checkpoint")
+		}
+		var ws address.WorkerState
+		copy(ws.Key[:], key)
+		ws.Attempts = w.Attempts
+		workers = append(workers, ws)
+	}
+	return address.NewSearchState(workers), nil
+}
+
+// checkpointValues returns the values of the checkpointParams flags
+func checkpointValues(fs *flag.FlagSet) map[string]string {
+	params := make(map[string]string)
+	for _, name := range checkpointParams {
+		params[name] = fs.Lookup(name).Value.String()
+	}
+	return params
+}
+
+// applyParams sets the flags to the parameters of the checkpoint.
+// Flags given on the command line have to match them.
+func (cp *checkpoint) applyParams(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	var names []string
+	for name := range cp.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown parameter -%s in checkpoint", name)
+		}
+		value := cp.Params[name]
+		if given[name] && f.Value.String() != value {
+			return fmt.Errorf("-%s %q does not match %q of the checkpoint", name, f.Value.String(), value)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid parameter -%s in checkpoint: %s", name, err.Error())
+		}
+	}
+	for _, name := range checkpointParams {
+		if _, ok := cp.Params[name]; !ok {
+			return fmt.Errorf("missing parameter -%s in checkpoint", name)
+		}
+	}
+	return nil
+}
+
+// open returns the checkpointer of the search, or nil if the search
+// is not checkpointed. When resuming, the flags of the search are set
+// to the parameters of the checkpoint.
+func (f *checkpointFlags) open(fs *flag.FlagSet, sf *searchFlags) (*checkpointer, error) {
+	switch {
+	case *f.path == "" && *f.resume == "":
+		return nil, nil
+	case *f.path != "" && *f.resume != "":
+		return nil, fmt.Errorf("-checkpoint and -resume are mutually exclusive, a resumed search updates its checkpoint")
+	case *f.path != "":
+		if _, err := os.Stat(*f.path); err == nil {
+			return nil, fmt.Errorf("checkpoint %s already exists, use -resume to continue its search", *f.path)
+		}
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	if *f.path != "" {
+		c, err := newCheckpointer(*f.path, *f.interval, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		c.cp = &checkpoint{
+			Params: checkpointValues(fs),
+		}
+		return c, nil
+	}
+
+	c, err := loadCheckpoint(*f.resume, *f.interval, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cp.applyParams(fs); err != nil {
+		return nil, err
+	}
+	if *sf.patterns != "" {
+		// Search for the patterns the search was started with,
+		// even if the patterns file changed in the meantime
+		sf.patternList = make([][]byte, len(c.cp.Patterns))
+		for i, p := range c.cp.Patterns {
+			sf.patternList[i] = []byte(p)
+		}
+	}
+	if c.cp.Found >= *sf.count {
+		return nil, fmt.Errorf("the search of checkpoint %s already found all %d addresses", *f.resume, c.cp.Found)
+	}
+	return c, nil
+}
+
+// prepare configures a search to be checkpointed
+func (c *checkpointer) prepare(opts *address.SearchOptions) error {
+	if opts.EachPattern {
+		return fmt.Errorf("-each-pattern searches can not be checkpointed")
+	}
+	if c.cp.Workers == nil {
+		c.cp.Patterns = nil
+		for _, p := range opts.Patterns {
+			c.cp.Patterns = append(c.cp.Patterns, string(p))
+		}
+		opts.State = address.NewSearchState(nil)
+		return nil
+	}
+	state, err := c.cp.state()
+	if err != nil {
+		return err
+	}
+	opts.State = state
+	opts.Count -= c.cp.Found
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+// checkpointFlagSet returns the flags of a checkpointed search
+func checkpointFlagSet(t *testing.T, args ...string) (*flag.FlagSet, *searchFlags, *checkpointFlags) {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.String("network", address.Mainnet.Name, "")
+	sf := registerSearchFlags(fs)
+	cf := registerCheckpointFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs, sf, cf
+}
+
+// setPassphrase sets the checkpoint passphrase and
+// returns a function which restores the previous one
+func setPassphrase(t *testing.T, passphrase string) func() {
+	old, ok := os.LookupEnv(passphraseEnv)
+	if err := os.Setenv(passphraseEnv, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			_ = os.Setenv(passphraseEnv, old)
+		} else {
+			_ = os.Unsetenv(passphraseEnv)
+		}
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "malvarmo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestDeriveKey(t *testing.T) {
+	// The published PBKDF2-HMAC-SHA256 counterparts
+	// of the PBKDF2-HMAC-SHA1 vectors of RFC 6070
+	for _, tc := range []struct {
+		password, salt string
+		iterations     int
+		key            string
+	}{
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	} {
+		if got := hex.EncodeToString(deriveKey([]byte(tc.password), []byte(tc.salt), tc.iterations)); got != tc.key {
+			t.Errorf("%q, %q, %d: got key %s, expected %s", tc.password, tc.salt, tc.iterations, got, tc.key)
+		}
+	}
+	if len(deriveKey([]byte("password"), []byte("salt"), 1)) != sha256.Size {
+		t.Fatal("expected a key of 32 bytes")
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "checkpoint")
+
+	c, err := newCheckpointer(path, time.Minute, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.cp = &checkpoint{
+		Params: map[string]string{"prefix": "a"},
+		Found:  2,
+	}
+	var ws address.WorkerState
+	ws.Key[0], ws.Attempts = 42, 1337
+	if err := c.save(address.NewSearchState([]address.WorkerState{ws})); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The worker positions are private keys and must not be readable
+	if bytes.Contains(data, []byte(hex.EncodeToString(ws.Key[:]))) {
+		t.Fatal("checkpoint contains the plain worker key")
+	}
+
+	loaded, err := loadCheckpoint(path, time.Minute, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.cp.Found != 2 || loaded.cp.Params["prefix"] != "a" {
+		t.Fatalf("got checkpoint %+v", loaded.cp)
+	}
+	state, err := loaded.cp.state()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Workers(); len(got) != 1 || got[0] != ws {
+		t.Fatalf("got worker states %+v, expected %+v", got, ws)
+	}
+
+	if _, err := loadCheckpoint(path, time.Minute, []byte("wrong horse")); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("expected wrong passphrase to be rejected, got %v", err)
+	}
+}
+
+func TestCheckpointApplyParams(t *testing.T) {
+	fs, _, _ := checkpointFlagSet(t, "-prefix", "b")
+	params := checkpointValues(fs)
+	params["prefix"] = "a"
+	cp := &checkpoint{Params: params}
+	if err := cp.applyParams(fs); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected mismatching -prefix to be rejected, got %v", err)
+	}
+
+	fs, sf, _ := checkpointFlagSet(t, "-workers", "1")
+	if err := cp.applyParams(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *sf.prefix != "a" {
+		t.Fatalf("got prefix %q, expected the prefix of the checkpoint", *sf.prefix)
+	}
+	if err := cp.applyParams(fs); err != nil {
+		t.Fatal("expected matching flags to be accepted:", err)
+	}
+
+	delete(params, "workers")
+	fs, _, _ = checkpointFlagSet(t)
+	if err := cp.applyParams(fs); err == nil {
+		t.Fatal("expected missing parameter to be rejected")
+	}
+}
+
+func TestCheckpointFlagsExclusive(t *testing.T) {
+	fs, sf, cf := checkpointFlagSet(t, "-prefix", "a", "-checkpoint", "a", "-resume", "b")
+	if _, err := cf.open(fs, sf); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected -checkpoint and -resume to be rejected, got %v", err)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	defer setPassphrase(t, "correct horse")()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "checkpoint")
+
+	// run searches until fn fails or the search is done
+	run := func(fn func(*address.Result) error, args ...string) (*checkpointer, error) {
+		fs, sf, cf := checkpointFlagSet(t, append([]string{"-quiet"}, args...)...)
+		ck, err := cf.open(fs, sf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts, err := sf.options(address.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ck.prepare(opts); err != nil {
+			t.Fatal(err)
+		}
+		return ck, sf.search(opts, ck, fn)
+	}
+
+	// The first search stops while handling its second address
+	var first []address.Result
+	stopped := errors.New("stopped")
+	if _, err := run(func(res *address.Result) error {
+		if first = append(first, *res); len(first) == 2 {
+			return stopped
+		}
+		return nil
+	}, "-checkpoint", path, "-prefix", "a", "-count", "3", "-workers", "1"); err != stopped {
+		t.Fatalf("got error %v, expected %v", err, stopped)
+	}
+
+	// The resumed search finds the missing addresses,
+	// starting with the one that was not handled
+	var second []address.Result
+	ck, err := run(func(res *address.Result) error {
+		second = append(second, *res)
+		return nil
+	}, "-resume", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 2 {
+		t.Fatalf("got %d addresses after resuming, expected 2", len(second))
+	}
+	if !bytes.Equal(second[0].Address, first[1].Address) || second[0].Attempts != first[1].Attempts {
+		t.Fatalf("got address %s after %d attempts, expected %s after %d", second[0].Address, second[0].Attempts, first[1].Address, first[1].Attempts)
+	}
+	if bytes.Equal(second[1].Address, first[0].Address) || second[1].Attempts <= second[0].Attempts {
+		t.Fatalf("got address %s after %d attempts again", second[1].Address, second[1].Attempts)
+	}
+	if ck.cp.Found != 3 {
+		t.Fatalf("got %d addresses found, expected 3", ck.cp.Found)
+	}
+
+	fs, sf, cf := checkpointFlagSet(t, "-resume", path)
+	if _, err := cf.open(fs, sf); err == nil {
+		t.Fatal("expected completed search to be rejected")
+	}
+}
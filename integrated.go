@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+// parseAddress parses the single address passed as argument
+func parseAddress(args []string) (*address.Address, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected exactly one address, got %d arguments", len(args))
+	}
+	addr, err := address.Parse(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", err.Error())
+	}
+	return addr, nil
+}
+
+func integrated(args []string) error {
+	fs := flagSet("integrated", "integrated [flags] <address>")
+	paymentID := fs.String("payment-id", "", "optional, the hex-encoded 8-byte payment ID, random if omitted")
+	parseFlags(fs, args)
+
+	addr, err := parseAddress(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var id []byte
+	if *paymentID == "" {
+		id, err = address.NewPaymentID()
+	} else {
+		id, err = hex.DecodeString(*paymentID)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid payment ID: %s", err.Error())
+	}
+
+	integratedAddr, err := address.NewIntegrated(addr, id)
+	if err != nil {
+		return fmt.Errorf("failed to create integrated address: %s", err.Error())
+	}
+
+	fmt.Println("Payment ID:        ", hex.EncodeToString(id))
+	fmt.Println("Integrated Address:", integratedAddr)
+
+	return nil
+}
+
+func split(args []string) error {
+	fs := flagSet("split", "split <integrated address>")
+	parseFlags(fs, args)
+
+	addr, err := parseAddress(fs.Args())
+	if err != nil {
+		return err
+	}
+	std, id, err := addr.Split()
+	if err != nil {
+		return fmt.Errorf("failed to split address: %s", err.Error())
+	}
+
+	fmt.Println("Payment ID:", hex.EncodeToString(id))
+	fmt.Println("Address:   ", std)
+
+	return nil
+}
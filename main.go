@@ -1,62 +1,166 @@
 package main
 
 import (
-	"bytes"
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/leonklingele/malvarmo/address"
+	"github.com/leonklingele/malvarmo/mnemonic"
 )
 
-func run(prefix []byte, numWorkers int) error {
-	var (
-		spendKeyPair, viewKeyPair *address.KeyPair
-		addr                      []byte
-		err                       error
-	)
-	if bytes.Equal([]byte{}, prefix) {
-		spendKeyPair, viewKeyPair, addr, err = address.New()
-	} else {
-		spendKeyPair, viewKeyPair, addr, err = address.NewWithPrefix(prefix, numWorkers)
+// flagSet returns a new flag set for a command
+// which is invoked with the given arguments
+func flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n", os.Args[0], args)
+		fs.PrintDefaults()
 	}
+	return fs
+}
+
+// parseFlags parses the flags of a command,
+// shows its help and exits if requested
+func parseFlags(fs *flag.FlagSet, args []string) {
+	showHelp := fs.Bool("help", false, "show help and exit")
+	_ = fs.Parse(args)
+
+	if *showHelp {
+		fs.Usage()
+		os.Exit(0)
+	}
+}
+
+func generate(args []string) error {
+	fs := flagSet("generate", "[command] [flags]\n\nCommands: "+strings.Join(commandNames(), ", ")+"\n")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network to create the address for (mainnet, testnet or stagenet)")
+	language := fs.String("language", mnemonic.English.EnglishName, "optional, the language of the mnemonic seed ("+languageNames()+")")
+	searchFlags := registerSearchFlags(fs)
+	checkpointFlags := registerCheckpointFlags(fs)
+	format := formatFlag(fs)
+	walletFile := walletFileFlags(fs)
+	parseFlags(fs, args)
+
+	// Check the format before searching, which may take days
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	ck, err := checkpointFlags.open(fs, searchFlags)
 	if err != nil {
-		return fmt.Errorf("failed to create new address: %s", err.Error())
+		return err
 	}
 
-	/*
-		Example output:
+	net, err := address.NetworkByName(*network)
+	if err != nil {
+		return err
+	}
+	lang, err := mnemonic.LanguageByName(*language)
+	if err != nil {
+		return err
+	}
+	opts, err := searchFlags.options(net)
+	if err != nil {
+		return err
+	}
 
-		Private Spend Key: dbcdb72ac43e2f3f9ca35c0b8fa8cee99759fce9e8d4fe84423186c39bb7260b
-		Public Spend Key:  85b84a94d9d7152660c28afffb03c8707e45277c950b24275f2b19db04d4f737
-		Private View Key:  6a5c667c9afd0b3256d9090b5aabbf83e592fc717d892ddf7df8275bb7a78400
-		Public View Key:   634e9804e703a9c7d05a6a1fc6dd17b45b60e14774140d1a1c710e1be0ccd120
-		Address:           46h1w3Z26Va7RKEY5SwD2XKpKsYQY7Qq97axQf2B3b8AAGLGUXr2FRAaRSok3pRHhQXAgvUcsvwJL5NK17egUqyS4euNvSp
-	*/
-	fmt.Println("Private Spend Key:", hex.EncodeToString(spendKeyPair.PrivateKey()))
-	fmt.Println("Public Spend Key: ", hex.EncodeToString(spendKeyPair.PublicKey()))
-	fmt.Println("Private View Key: ", hex.EncodeToString(viewKeyPair.PrivateKey()))
-	fmt.Println("Public View Key:  ", hex.EncodeToString(viewKeyPair.PublicKey()))
-	fmt.Println("Address:          ", string(addr))
+	multiple := searchFlags.multiple()
+	if multiple && *walletFile.path != "" {
+		return fmt.Errorf("-wallet-json can only be used for a single address")
+	}
+	if multiple && *format == "env" {
+		return fmt.Errorf("-format env can only be used for a single address")
+	}
+	var n int
+	emit := func(res *address.Result) error {
+		if n++; n > 1 {
+			printSeparator(os.Stdout, *format)
+		}
+		return printResult(net, res, lang, *format, multiple, walletFile)
+	}
 
-	return nil
+	if opts == nil {
+		if ck != nil {
+			return fmt.Errorf("only vanity searches can be checkpointed")
+		}
+		for i := 0; i < *searchFlags.count; i++ {
+			spendKeyPair, viewKeyPair, addr, err := address.New(net)
+			if err != nil {
+				return fmt.Errorf("failed to create new address: %s", err.Error())
+			}
+			if err := emit(&address.Result{
+				SpendKeyPair: spendKeyPair,
+				ViewKeyPair:  viewKeyPair,
+				Address:      addr,
+				Attempts:     1,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if ck != nil {
+		if err := ck.prepare(opts); err != nil {
+			return err
+		}
+	}
+	return searchFlags.search(opts, ck, emit)
 }
 
-func main() {
-	prefix := flag.String("prefix", "", "optional, the address prefix to search for")
-	numWorkers := flag.Int("workers", runtime.GOMAXPROCS(-1), "optional, the number of workers to use for prefix search")
-	showHelp := flag.Bool("help", false, "show help and exit")
-	flag.Parse()
+// printResult prints a wallet and writes its wallet file if requested.
+// lines is set if several wallets are printed, see printWallet.
+func printResult(net address.Network, res *address.Result, lang *mnemonic.Language, format string, lines bool, walletFile *walletFileOptions) error {
+	w, err := newWallet(net, res, lang)
+	if err != nil {
+		return err
+	}
+	if err := printWallet(os.Stdout, w, format, lines); err != nil {
+		return err
+	}
+	return walletFile.write(res.SpendKeyPair.PrivateKey(), res.ViewKeyPair.PrivateKey(), w.Seed, w.Address)
+}
 
-	if *showHelp {
-		flag.Usage()
-		os.Exit(0)
+// commands maps all command names to their implementations
+func commands() map[string]func([]string) error {
+	return map[string]func([]string) error{
+		"convert":       convert,
+		"generate":      generate,
+		"integrated":    integrated,
+		"restore":       restore,
+		"split":         split,
+		"split-combine": splitCombine,
+		"split-init":    splitInit,
+		"split-search":  splitSearch,
+		"subaddress":    subaddress,
+		"watch-only":    watchOnly,
+	}
+}
+
+// commandNames returns the sorted names of all commands
+func commandNames() []string {
+	var names []string
+	for name := range commands() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	args := os.Args[1:]
+	cmd := generate
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		var ok bool
+		if cmd, ok = commands()[args[0]]; !ok {
+			log.Fatalf("unknown command %q", args[0])
+		}
+		args = args[1:]
 	}
 
-	if err := run([]byte(*prefix), *numWorkers); err != nil {
+	if err := cmd(args); err != nil {
 		log.Fatal(err)
 	}
 }
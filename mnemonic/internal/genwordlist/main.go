@@ -0,0 +1,141 @@
+// genwordlist converts the seed wordlists of Monero, located at
+// src/mnemonics/*.h in the Monero source tree, into Go source files
+// which it writes to the current directory.
+//
+// Usage: go run internal/genwordlist/main.go -in /path/to/monero/src/mnemonics
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	numWords = 1626
+	nameRe   = `(?:std::string\(\s*)?"([^"]*)"(?:\s*\))?`
+)
+
+var (
+	// Each wordlist is defined as
+	// Base("<native name>", std::string("<english name>"), { "<word>", ... }, <unique prefix length>)
+	// where either name may or may not be wrapped in std::string
+	baseRe = regexp.MustCompile(`(?s)Base\(\s*` + nameRe + `\s*,\s*` + nameRe + `\s*,[^{]*\{(.*?)\}\s*\)?\s*,\s*(\d+)\s*\)`)
+	wordRe = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+)
+
+type wordlist struct {
+	name, englishName string
+	prefixLen         int
+	words             []string
+}
+
+func parse(data []byte) (*wordlist, error) {
+	m := baseRe.FindSubmatch(data)
+	if m == nil {
+		return nil, fmt.Errorf("no wordlist definition found")
+	}
+	prefixLen, err := strconv.Atoi(string(m[4]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid unique prefix length: %s", err.Error())
+	}
+	wl := &wordlist{
+		name:        string(m[1]),
+		englishName: string(m[2]),
+		prefixLen:   prefixLen,
+	}
+	prefixes := make(map[string]bool, numWords)
+	for _, wm := range wordRe.FindAllSubmatch(m[3], -1) {
+		word, err := strconv.Unquote(`"` + string(wm[1]) + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid word %s: %s", wm[1], err.Error())
+		}
+		prefix := word
+		if utf8.RuneCountInString(word) > prefixLen {
+			prefix = string([]rune(word)[:prefixLen])
+		}
+		if prefixes[prefix] {
+			return nil, fmt.Errorf("duplicate prefix %q", prefix)
+		}
+		prefixes[prefix] = true
+		wl.words = append(wl.words, word)
+	}
+	if len(wl.words) != numWords {
+		return nil, fmt.Errorf("got %d words, expected %d", len(wl.words), numWords)
+	}
+	return wl, nil
+}
+
+// identifier turns the English name of a language into a Go identifier
+func identifier(englishName string) string {
+	fields := strings.FieldsFunc(englishName, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, f := range fields {
+		f = strings.ToLower(f)
+		if i > 0 {
+			f = strings.Title(f)
+		}
+		fields[i] = f
+	}
+	return strings.Join(fields, "")
+}
+
+func generate(src string, wl *wordlist) ([]byte, error) {
+	id := identifier(wl.englishName)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by genwordlist from %s; DO NOT EDIT.\n\n", src)
+	fmt.Fprintf(&buf, "package mnemonic\n\n")
+	fmt.Fprintf(&buf, "func init() {\n\tregister(%s)\n}\n\n", id)
+	fmt.Fprintf(&buf, "var %s = &Language{\n", id)
+	fmt.Fprintf(&buf, "Name: %q,\nEnglishName: %q,\nprefixLen: %d,\nwords: []string{\n", wl.name, wl.englishName, wl.prefixLen)
+	for _, w := range wl.words {
+		fmt.Fprintf(&buf, "%q,\n", w)
+	}
+	fmt.Fprintf(&buf, "},\n}\n")
+	return format.Source(buf.Bytes())
+}
+
+func main() {
+	in := flag.String("in", "", "the directory containing the Monero wordlists")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join(*in, "*.h"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		wl, err := parse(data)
+		if err != nil {
+			// Not every header defines a wordlist
+			log.Printf("skipping %s: %s", file, err.Error())
+			continue
+		}
+		if wl.englishName == "English" {
+			// English is built in
+			continue
+		}
+		src, err := generate(filepath.Base(file), wl)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out := "wordlist_" + strings.ToLower(identifier(wl.englishName)) + ".go"
+		if err := ioutil.WriteFile(out, src, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("generated %s with %d words", out, len(wl.words))
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// header returns a wordlist header laid out like the ones in
+// src/mnemonics of the Monero source tree, with the words
+// replaced by numbered ones with unique four-letter prefixes
+func header(class, base string, n int) []byte {
+	var words []string
+	for i := 0; i < n; i++ {
+		words = append(words, fmt.Sprintf(`        "%04dwort"`, i))
+	}
+	return []byte(`// Word list originally created by dabura667 and released under The MIT License (MIT)
+
+#ifndef ` + strings.ToUpper(class) + `_H
+#define ` + strings.ToUpper(class) + `_H
+
+#include <vector>
+#include <unordered_map>
+#include "language_base.h"
+#include <string>
+
+namespace Language
+{
+  class ` + class + `: public Base
+  {
+  public:
+    ` + class + `(): ` + base + `, {
+` + strings.Join(words, ",\n") + `
+      }, 4)
+    {
+      populate_maps();
+    }
+  };
+}
+
+#endif
+`)
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		class, base       string
+		name, englishName string
+	}{
+		{"German", `Base("Deutsch", std::string("German")`, "Deutsch", "German"},
+		{"German", `Base(std::string("Deutsch"), std::string("German")`, "Deutsch", "German"},
+		{"German", `Base("Deutsch", "German"`, "Deutsch", "German"},
+		{"Chinese_Simplified", `Base("简体中文 (中国)", std::string("Chinese (simplified)")`, "简体中文 (中国)", "Chinese (simplified)"},
+	} {
+		wl, err := parse(header(tc.class, tc.base, numWords))
+		if err != nil {
+			t.Fatalf("%s: %s", tc.base, err.Error())
+		}
+		if wl.name != tc.name || wl.englishName != tc.englishName || wl.prefixLen != 4 {
+			t.Fatalf("%s: got %q, %q, %d", tc.base, wl.name, wl.englishName, wl.prefixLen)
+		}
+		if len(wl.words) != numWords || wl.words[0] != "0000wort" || wl.words[numWords-1] != "1625wort" {
+			t.Fatalf("%s: got %d words from %q to %q", tc.base, len(wl.words), wl.words[0], wl.words[len(wl.words)-1])
+		}
+	}
+
+	if _, err := parse(header("German", `Base("Deutsch", std::string("German")`, numWords-1)); err == nil {
+		t.Fatal("expected incomplete wordlist to be rejected")
+	}
+	if _, err := parse([]byte("#include <string>\n")); err == nil {
+		t.Fatal("expected header without a wordlist to be rejected")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	wl, err := parse(header("Chinese_Simplified", `Base("简体中文 (中国)", std::string("Chinese (simplified)")`, numWords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate("chinese_simplified.h", wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"register(chineseSimplified)",
+		`Name:        "简体中文 (中国)"`,
+		`EnglishName: "Chinese (simplified)"`,
+		`"1625wort",`,
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("expected generated source to contain %s:\n%.400s", s, src)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package mnemonic
+
+//go:generate go run internal/genwordlist/main.go -in $MONERO_SRC/src/mnemonics
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// languages are the available languages, see register
+	languages = []*Language{English}
+	// moneroLanguages holds the native name and the unique prefix
+	// length of every seed language Monero ships, by English name
+	moneroLanguages = map[string]struct {
+		name      string
+		prefixLen int
+	}{
+		"English":              {"English", 3},
+		"Spanish":              {"Español", 4},
+		"German":               {"Deutsch", 4},
+		"Italian":              {"Italiano", 4},
+		"Portuguese":           {"Português", 4},
+		"French":               {"Français", 4},
+		"Japanese":             {"日本語", 3},
+		"Chinese (simplified)": {"简体中文 (中国)", 1},
+		"Russian":              {"русский язык", 4},
+		"Esperanto":            {"Esperanto", 4},
+		"Lojban":               {"Lojban", 4},
+		"Dutch":                {"Nederlands", 4},
+	}
+)
+
+// register makes a language available to LanguageByName and Detect
+func register(l *Language) {
+	languages = append(languages, l)
+}
+
+// Languages returns all available languages
+func Languages() []*Language {
+	return append([]*Language(nil), languages...)
+}
+
+// LanguageByName returns the language with the given native or English name
+func LanguageByName(name string) (*Language, error) {
+	for _, l := range languages {
+		if strings.EqualFold(l.Name, name) || strings.EqualFold(l.EnglishName, name) {
+			return l, nil
+		}
+	}
+	for englishName, l := range moneroLanguages {
+		if strings.EqualFold(l.name, name) || strings.EqualFold(englishName, name) {
+			return nil, fmt.Errorf("the %s wordlist is not bundled, generate it with MONERO_SRC=/path/to/monero go generate ./mnemonic", englishName)
+		}
+	}
+	return nil, fmt.Errorf("unknown language %q", name)
+}
+
+// Detect returns the language of a seed. Languages whose wordlist
+// contains all words in full are preferred over those which only
+// contain their unique prefixes.
+func Detect(words []string) (*Language, error) {
+	var matches, exact []*Language
+	for _, l := range languages {
+		found, complete := true, true
+		for _, w := range words {
+			if _, ok := l.lookup(w); !ok {
+				found = false
+				break
+			}
+			complete = complete && l.contains(w)
+		}
+		if !found {
+			continue
+		}
+		matches = append(matches, l)
+		if complete {
+			exact = append(exact, l)
+		}
+	}
+	if len(exact) > 0 {
+		matches = exact
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("unknown seed language")
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("ambiguous seed language, could be any of %s", matches)
+}
@@ -0,0 +1,172 @@
+package mnemonic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+const (
+	// seedSize is the size of the private spend key a seed encodes
+	seedSize = 32
+	// NumWords is the number of words of a seed, including the checksum word
+	NumWords = seedSize/4*3 + 1
+)
+
+// Language is a wordlist seeds can be encoded with
+type Language struct {
+	// Name is the native name of the language
+	Name string
+	// EnglishName is the English name of the language
+	EnglishName string
+	// prefixLen is the number of leading characters
+	// which uniquely identify a word of the wordlist
+	prefixLen int
+	words     []string
+
+	once         sync.Once
+	index, folds map[string]int
+}
+
+// English is the built-in English wordlist
+var English = &Language{
+	Name:        "English",
+	EnglishName: "English",
+	prefixLen:   3,
+	words:       englishWords,
+}
+
+func (l *Language) String() string {
+	return l.EnglishName
+}
+
+// prefix returns the unique prefix of word
+func (l *Language) prefix(word string) string {
+	if utf8.RuneCountInString(word) <= l.prefixLen {
+		return word
+	}
+	var n int
+	for i := range word {
+		if n == l.prefixLen {
+			return word[:i]
+		}
+		n++
+	}
+	return word
+}
+
+// lookup returns the index of word in the wordlist.
+// Words may be abbreviated to their unique prefix.
+func (l *Language) lookup(word string) (int, bool) {
+	l.once.Do(func() {
+		l.index = make(map[string]int, len(l.words))
+		l.folds = make(map[string]int, len(l.words))
+		for i, w := range l.words {
+			l.index[l.prefix(w)] = i
+			// Only allow case-insensitive lookups of unambiguous prefixes
+			fold := l.prefix(strings.ToLower(w))
+			if _, ok := l.folds[fold]; ok {
+				i = -1
+			}
+			l.folds[fold] = i
+		}
+	})
+	if i, ok := l.index[l.prefix(word)]; ok {
+		return i, true
+	}
+	i, ok := l.folds[l.prefix(strings.ToLower(word))]
+	return i, ok && i >= 0
+}
+
+// contains reports whether word is a complete word of the wordlist
+func (l *Language) contains(word string) bool {
+	i, ok := l.lookup(word)
+	return ok && strings.EqualFold(l.words[i], word)
+}
+
+// checksumWord returns the word which checksums the given seed words
+func (l *Language) checksumWord(words []string) string {
+	trimmed := make([]string, len(words))
+	for i, w := range words {
+		trimmed[i] = l.prefix(w)
+	}
+	sum := crc32.ChecksumIEEE([]byte(strings.Join(trimmed, "")))
+	return words[sum%uint32(len(words))]
+}
+
+// Encode converts a private spend key into a
+// 25-word seed, including its checksum word
+func Encode(key address.PrivateKey, lang *Language) ([]string, error) {
+	if len(key) != seedSize {
+		return nil, fmt.Errorf("invalid key length %d, expected %d", len(key), seedSize)
+	}
+	n := uint32(len(lang.words))
+	words := make([]string, 0, NumWords)
+	for i := 0; i < seedSize; i += 4 {
+		// Every 4 bytes of the key are encoded into 3 words
+		x := binary.LittleEndian.Uint32(key[i : i+4])
+		w1 := x % n
+		w2 := (x/n + w1) % n
+		w3 := (x/n/n + w2) % n
+		words = append(words, lang.words[w1], lang.words[w2], lang.words[w3])
+	}
+	return append(words, lang.checksumWord(words)), nil
+}
+
+// Decode restores the spend and view key pairs from a seed.
+// The checksum word is verified if present.
+func Decode(words []string, lang *Language) (*address.KeyPair, *address.KeyPair, error) {
+	if len(words) != NumWords && len(words) != NumWords-1 {
+		return nil, nil, fmt.Errorf("invalid number of words %d, expected %d", len(words), NumWords)
+	}
+	n := uint32(len(lang.words))
+	seed := make([]byte, seedSize)
+	for i := 0; i < seedSize/4; i++ {
+		var idx [3]uint32
+		for j, w := range words[3*i : 3*i+3] {
+			k, ok := lang.lookup(w)
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid word %q", w)
+			}
+			idx[j] = uint32(k)
+		}
+		w1, w2, w3 := idx[0], idx[1], idx[2]
+		x := w1 + n*((n-w1+w2)%n) + n*n*((n-w2+w3)%n)
+		if x%n != w1 {
+			return nil, nil, fmt.Errorf("invalid word sequence %q", words[3*i:3*i+3])
+		}
+		binary.LittleEndian.PutUint32(seed[4*i:], x)
+	}
+	if len(words) == NumWords {
+		// Compare the checksum word using the canonical words
+		canonical := make([]string, NumWords-1)
+		for i, w := range words[:NumWords-1] {
+			k, _ := lang.lookup(w)
+			canonical[i] = lang.words[k]
+		}
+		checksum, ok := lang.lookup(words[NumWords-1])
+		if !ok || lang.words[checksum] != lang.checksumWord(canonical) {
+			return nil, nil, fmt.Errorf("invalid checksum word %q", words[NumWords-1])
+		}
+	}
+	return address.FromSeed(seed)
+}
+
+// Convert re-encodes a seed in another language.
+// The keys the seed encodes remain unchanged.
+func Convert(words []string, to *Language) ([]string, error) {
+	from, err := Detect(words)
+	if err != nil {
+		return nil, err
+	}
+	spendKeyPair, _, err := Decode(words, from)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(spendKeyPair.PrivateKey(), to)
+}
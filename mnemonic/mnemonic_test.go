@@ -0,0 +1,228 @@
+package mnemonic
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+type fixture struct {
+	seed,
+	privSpendHex, pubSpendHex,
+	privViewHex string
+}
+
+var (
+	fixtures = []fixture{
+		{
+			"sequence atlas unveil summon pebbles tuesday beer rudely snake rockets different fuselage woven tagged bested dented vegan hover rapid fawns obvious muppet randomly seasons randomly",
+			"b0ef6bd527b9b23b9ceef70dc8b4cd1ee83ca14541964e764ad23f5151204f0f",
+			"7d996b0f2db6dbb5f2a086211f2399a4a7479b2c911af307fdc3f7f61a88cb0e",
+			"42ba20adb337e5eca797565be11c9adb0a8bef8c830bccc2df712535d3b8f608",
+		},
+	}
+)
+
+func TestEncode(t *testing.T) {
+	for i, fx := range fixtures {
+		words, err := Encode(h2b(fx.privSpendHex), English)
+		if err != nil {
+			t.Fatalf("failed at fixture %d: %s", i, err.Error())
+		}
+		if got := strings.Join(words, " "); got != fx.seed {
+			t.Fatalf("failed at fixture %d: got incorrect seed: %s", i, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for i, fx := range fixtures {
+		for _, words := range [][]string{
+			strings.Fields(fx.seed),
+			strings.Fields(fx.seed)[:NumWords-1],
+			abbreviate(strings.Fields(fx.seed), English.prefixLen),
+			strings.Fields(strings.ToUpper(fx.seed)),
+		} {
+			spendKeyPair, viewKeyPair, err := Decode(words, English)
+			if err != nil {
+				t.Fatalf("failed at fixture %d: %s", i, err.Error())
+			}
+			if got := hex.EncodeToString(spendKeyPair.PrivateKey()); got != fx.privSpendHex {
+				t.Fatalf("failed at fixture %d: got incorrect private spend key: %s", i, got)
+			}
+			if got := hex.EncodeToString(spendKeyPair.PublicKey()); got != fx.pubSpendHex {
+				t.Fatalf("failed at fixture %d: got incorrect public spend key: %s", i, got)
+			}
+			if got := hex.EncodeToString(viewKeyPair.PrivateKey()); got != fx.privViewHex {
+				t.Fatalf("failed at fixture %d: got incorrect private view key: %s", i, got)
+			}
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	words := strings.Fields(fixtures[0].seed)
+	invalid := map[string][]string{
+		"too short":      words[:NumWords-2],
+		"unknown word":   append([]string{"malvarmo"}, words[1:]...),
+		"wrong checksum": append(append([]string{}, words[:NumWords-1]...), "abbey"),
+	}
+	for name, words := range invalid {
+		if _, _, err := Decode(words, English); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	spendKeyPair, _, _, err := address.New(address.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words, err := Encode(spendKeyPair.PrivateKey(), English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := Decode(words, English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.PrivateKey()) != string(spendKeyPair.PrivateKey()) {
+		t.Fatalf("got incorrect private spend key: %x", got.PrivateKey())
+	}
+}
+
+func abbreviate(words []string, n int) []string {
+	res := make([]string, len(words))
+	for i, w := range words {
+		if len(w) > n {
+			w = w[:n]
+		}
+		res[i] = w
+	}
+	return res
+}
+
+func h2b(h string) []byte {
+	dec, err := hex.DecodeString(h)
+	if err != nil {
+		panic(err)
+	}
+	return dec
+}
+
+func TestLanguageByName(t *testing.T) {
+	for _, name := range []string{"English", "english"} {
+		if l, err := LanguageByName(name); err != nil || l != English {
+			t.Fatalf("got incorrect language for %q: %v, %v", name, l, err)
+		}
+	}
+	if _, err := LanguageByName("Klingon"); err == nil {
+		t.Fatal("expected error for unknown language")
+	}
+}
+
+var (
+	// languageVectors holds a known-answer seed for each
+	// language, taken from Monero, by English name
+	languageVectors = map[string]struct {
+		seed, privSpendHex string
+	}{
+		"English": {fixtures[0].seed, fixtures[0].privSpendHex},
+	}
+)
+
+func TestLanguages(t *testing.T) {
+	for _, l := range Languages() {
+		want, ok := moneroLanguages[l.EnglishName]
+		if !ok {
+			t.Fatalf("%s is not a Monero seed language", l.EnglishName)
+		}
+		if l.Name != want.name || l.prefixLen != want.prefixLen {
+			t.Fatalf("%s: got name %q and unique prefix length %d, expected %q and %d", l.EnglishName, l.Name, l.prefixLen, want.name, want.prefixLen)
+		}
+		if len(l.words) != len(englishWords) {
+			t.Fatalf("%s: got %d words, expected %d", l.EnglishName, len(l.words), len(englishWords))
+		}
+
+		vector, ok := languageVectors[l.EnglishName]
+		if !ok {
+			t.Fatalf("%s: no known-answer seed, add one taken from Monero", l.EnglishName)
+		}
+		words := strings.Fields(vector.seed)
+		if got, err := Detect(words); err != nil || got != l {
+			t.Fatalf("%s: got language %v, %v", l.EnglishName, got, err)
+		}
+		spendKeyPair, _, err := Decode(words, l)
+		if err != nil {
+			t.Fatalf("%s: %s", l.EnglishName, err)
+		}
+		if got := hex.EncodeToString(spendKeyPair.PrivateKey()); got != vector.privSpendHex {
+			t.Fatalf("%s: got private spend key %s, expected %s", l.EnglishName, got, vector.privSpendHex)
+		}
+		enc, err := Encode(spendKeyPair.PrivateKey(), l)
+		if err != nil {
+			t.Fatalf("%s: %s", l.EnglishName, err)
+		}
+		if got := strings.Join(enc, " "); got != strings.Join(words, " ") {
+			t.Fatalf("%s: got seed %s, expected %s", l.EnglishName, got, vector.seed)
+		}
+	}
+
+	// Languages which are not bundled point to the generator
+	if len(languages) < len(moneroLanguages) {
+		for englishName := range moneroLanguages {
+			if _, err := LanguageByName(englishName); err != nil && !strings.Contains(err.Error(), "go generate") {
+				t.Fatalf("%s: got error %q", englishName, err)
+			}
+		}
+	}
+}
+
+func TestDetectAndConvert(t *testing.T) {
+	// Register a fake language which uses altered English words in reverse order
+	reversed := make([]string, len(englishWords))
+	for i, w := range englishWords {
+		reversed[len(reversed)-1-i] = strings.ToUpper("x" + w)
+	}
+	fake := &Language{Name: "Hsilgne", EnglishName: "Reversed English", prefixLen: 4, words: reversed}
+	defer func(l []*Language) { languages = l }(languages)
+	register(fake)
+
+	fx := fixtures[0]
+	words := strings.Fields(fx.seed)
+	if l, err := Detect(words); err != nil || l != English {
+		t.Fatalf("got incorrect language: %v, %v", l, err)
+	}
+	if l, err := Detect(abbreviate(words, English.prefixLen)); err != nil || l != English {
+		t.Fatalf("got incorrect language: %v, %v", l, err)
+	}
+	if _, err := Detect([]string{"qwerty"}); err == nil {
+		t.Fatal("expected error for unknown language")
+	}
+
+	converted, err := Convert(words, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, err := Detect(converted); err != nil || l != fake {
+		t.Fatalf("got incorrect language: %v, %v", l, err)
+	}
+	spendKeyPair, _, err := Decode(converted, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(spendKeyPair.PrivateKey()); got != fx.privSpendHex {
+		t.Fatalf("got incorrect private spend key: %s", got)
+	}
+
+	back, err := Convert(converted, English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(back, " "); got != fx.seed {
+		t.Fatalf("got incorrect seed: %s", got)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leonklingele/malvarmo/address"
+	"github.com/leonklingele/malvarmo/mnemonic"
+)
+
+// wallet describes a generated or restored wallet.
+// Its fields make up the schema of all machine-readable
+// output formats and must therefore remain stable.
+type wallet struct {
+	Network         string    `json:"network"`
+	Address         string    `json:"address"`
+	PrivateSpendKey string    `json:"private_spend_key"`
+	PublicSpendKey  string    `json:"public_spend_key"`
+	PrivateViewKey  string    `json:"private_view_key"`
+	PublicViewKey   string    `json:"public_view_key"`
+	Seed            string    `json:"seed,omitempty"`
+	SeedLanguage    string    `json:"seed_language,omitempty"`
+	VanityPattern   string    `json:"vanity_pattern,omitempty"`
+	VanityMatch     string    `json:"vanity_match,omitempty"`
+	Attempts        uint64    `json:"attempts"`
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// newWallet describes the wallet of a search result.
+// Its seed is encoded in the given language.
+func newWallet(net address.Network, res *address.Result, lang *mnemonic.Language) (*wallet, error) {
+	seed, err := mnemonic.Encode(res.SpendKeyPair.PrivateKey(), lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mnemonic seed: %s", err.Error())
+	}
+	// A seed only restores the view key derived from the spend key,
+	// which the view key of a split-key search is not
+	_, viewKeyPair, err := address.FromPrivateSpendKey(res.SpendKeyPair.PrivateKey())
+	if err != nil || !bytes.Equal(viewKeyPair.PrivateKey(), res.ViewKeyPair.PrivateKey()) {
+		seed = nil
+	}
+	var seedLanguage string
+	if seed != nil {
+		seedLanguage = lang.EnglishName
+	}
+	return &wallet{
+		Network:         net.Name,
+		Address:         string(res.Address),
+		PrivateSpendKey: hex.EncodeToString(res.SpendKeyPair.PrivateKey()),
+		PublicSpendKey:  hex.EncodeToString(res.SpendKeyPair.PublicKey()),
+		PrivateViewKey:  hex.EncodeToString(res.ViewKeyPair.PrivateKey()),
+		PublicViewKey:   hex.EncodeToString(res.ViewKeyPair.PublicKey()),
+		Seed:            strings.Join(seed, " "),
+		SeedLanguage:    seedLanguage,
+		VanityPattern:   string(res.Pattern),
+		VanityMatch:     string(res.Match),
+		Attempts:        res.Attempts,
+		GeneratedAt:     time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// field is a key-value pair of the machine-readable output
+type field struct {
+	key, value string
+	// quote is set for string values
+	quote bool
+}
+
+// fields returns the fields of the wallet in schema order
+func (w *wallet) fields() []field {
+	fs := []field{
+		{"network", w.Network, true},
+		{"address", w.Address, true},
+		{"private_spend_key", w.PrivateSpendKey, true},
+		{"public_spend_key", w.PublicSpendKey, true},
+		{"private_view_key", w.PrivateViewKey, true},
+		{"public_view_key", w.PublicViewKey, true},
+		{"seed", w.Seed, true},
+		{"seed_language", w.SeedLanguage, true},
+		{"vanity_pattern", w.VanityPattern, true},
+		{"vanity_match", w.VanityMatch, true},
+		{"attempts", strconv.FormatUint(w.Attempts, 10), false},
+		{"generated_at", w.GeneratedAt.Format(time.RFC3339), true},
+	}
+	return nonEmpty(fs)
+}
+
+// nonEmpty returns the fields which have a value
+func nonEmpty(fs []field) []field {
+	res := fs[:0]
+	for _, f := range fs {
+		if f.value != "" {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// formats are the output formats, the first one is the default
+var formats = []string{"text", "json", "yaml", "env"}
+
+// formatFlag registers the flag to choose the output format
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", formats[0], "optional, the output format ("+strings.Join(formats, ", ")+")")
+}
+
+// checkFormat returns an error if format is not one of formats
+func checkFormat(format string) error {
+	for _, f := range formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// printSeparator separates wallets printed in the given format.
+// Several wallets are printed as newline-delimited JSON, which
+// needs no separator, and can not be printed as env.
+func printSeparator(out io.Writer, format string) {
+	if format == "yaml" {
+		fmt.Fprintln(out, "---")
+		return
+	}
+	if format != "json" {
+		fmt.Fprintln(out)
+	}
+}
+
+// printJSON prints v as indented JSON, or as a single line
+// of newline-delimited JSON if lines is set
+func printJSON(out io.Writer, v interface{}, lines bool) error {
+	enc := json.NewEncoder(out)
+	if !lines {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
+// printWallet prints the wallet in the given format. If lines is set,
+// several wallets are printed and json prints one wallet per line.
+func printWallet(out io.Writer, w *wallet, format string, lines bool) error {
+	switch format {
+	case "text":
+		/*
+			Example output:
+
+			Private Spend Key: dbcdb72ac43e2f3f9ca35c0b8fa8cee99759fce9e8d4fe84423186c39bb7260b
+			Public Spend Key:  85b84a94d9d7152660c28afffb03c8707e45277c950b24275f2b19db04d4f737
+			Private View Key:  6a5c667c9afd0b3256d9090b5aabbf83e592fc717d892ddf7df8275bb7a78400
+			Public View Key:   634e9804e703a9c7d05a6a1fc6dd17b45b60e14774140d1a1c710e1be0ccd120
+			Mnemonic Seed:     ...
+			Address:           46h1w3Z26Va7RKEY5SwD2XKpKsYQY7Qq97axQf2B3b8AAGLGUXr2FRAaRSok3pRHhQXAgvUcsvwJL5NK17egUqyS4euNvSp
+		*/
+		fmt.Fprintln(out, "Private Spend Key:", w.PrivateSpendKey)
+		fmt.Fprintln(out, "Public Spend Key: ", w.PublicSpendKey)
+		fmt.Fprintln(out, "Private View Key: ", w.PrivateViewKey)
+		fmt.Fprintln(out, "Public View Key:  ", w.PublicViewKey)
+		if w.Seed != "" {
+			fmt.Fprintln(out, "Mnemonic Seed:    ", w.Seed)
+		}
+		fmt.Fprintln(out, "Address:          ", w.Address)
+		if w.VanityPattern != "" {
+			fmt.Fprintln(out, "Vanity Pattern:   ", w.VanityPattern)
+			fmt.Fprintln(out, "Vanity Match:     ", w.VanityMatch)
+		}
+	case "json":
+		return printJSON(out, w, lines)
+	default:
+		return printFields(out, w.fields(), format)
+	}
+	return nil
+}
+
+// printFields prints fields in the yaml or env format
+func printFields(out io.Writer, fs []field, format string) error {
+	switch format {
+	case "yaml":
+		for _, f := range fs {
+			value := f.value
+			if f.quote {
+				value = strconv.Quote(value)
+			}
+			fmt.Fprintf(out, "%s: %s\n", f.key, value)
+		}
+	case "env":
+		for _, f := range fs {
+			// Single-quote values so the output can be sourced by a shell
+			value := "'" + strings.Replace(f.value, "'", `'\''`, -1) + "'"
+			fmt.Fprintf(out, "MALVARMO_%s=%s\n", strings.ToUpper(f.key), value)
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWallet returns a wallet without a seed whose vanity
+// pattern needs quoting in every format
+func testWallet() *wallet {
+	return &wallet{
+		Network:         "mainnet",
+		Address:         "46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN",
+		PrivateSpendKey: "4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400",
+		PublicSpendKey:  "7849297236cd7c0d6c69a3c8c179c038d3c1c434735741bb3c8995c3c9d6f2ac",
+		PrivateViewKey:  "e514321d6163c9c222f22eb9f43dd1421aee455bb87adb9e0aee138aa8b4b806",
+		PublicViewKey:   "c3fb70733f47f076a70766bfc3ff074e7b7c2663e65394790cc214549458d28e",
+		VanityPattern:   `^4.(B'V|"x")`,
+		VanityMatch:     "46BV",
+		Attempts:        1337,
+		GeneratedAt:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPrintWalletJSON(t *testing.T) {
+	want := map[string]interface{}{
+		"network":           "mainnet",
+		"address":           testWallet().Address,
+		"private_spend_key": testWallet().PrivateSpendKey,
+		"public_spend_key":  testWallet().PublicSpendKey,
+		"private_view_key":  testWallet().PrivateViewKey,
+		"public_view_key":   testWallet().PublicViewKey,
+		"vanity_pattern":    `^4.(B'V|"x")`,
+		"vanity_match":      "46BV",
+		"attempts":          1337.0,
+		"generated_at":      "2020-01-01T00:00:00Z",
+	}
+	for _, lines := range []bool{false, true} {
+		var out bytes.Buffer
+		for i := 0; i < 2; i++ {
+			if i > 0 {
+				printSeparator(&out, "json")
+			}
+			if err := printWallet(&out, testWallet(), "json", lines); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Newline-delimited JSON has one wallet per line
+		if n := strings.Count(out.String(), "\n"); lines && n != 2 {
+			t.Fatalf("got %d lines of newline-delimited JSON:\n%s", n, out.String())
+		}
+		dec := json.NewDecoder(&out)
+		for i := 0; i < 2; i++ {
+			var got map[string]interface{}
+			if err := dec.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			// The empty seed and its language are omitted
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("lines %t: got wallet %v, expected %v", lines, got, want)
+			}
+		}
+	}
+}
+
+func TestPrintWalletYAMLEnv(t *testing.T) {
+	for _, tc := range []struct {
+		format, want string
+	}{
+		{"yaml", `network: "mainnet"
+address: "46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN"
+private_spend_key: "4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400"
+public_spend_key: "7849297236cd7c0d6c69a3c8c179c038d3c1c434735741bb3c8995c3c9d6f2ac"
+private_view_key: "e514321d6163c9c222f22eb9f43dd1421aee455bb87adb9e0aee138aa8b4b806"
+public_view_key: "c3fb70733f47f076a70766bfc3ff074e7b7c2663e65394790cc214549458d28e"
+vanity_pattern: "^4.(B'V|\"x\")"
+vanity_match: "46BV"
+attempts: 1337
+generated_at: "2020-01-01T00:00:00Z"
+`},
+		{"env", `MALVARMO_NETWORK='mainnet'
+MALVARMO_ADDRESS='46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN'
+MALVARMO_PRIVATE_SPEND_KEY='4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400'
+MALVARMO_PUBLIC_SPEND_KEY='7849297236cd7c0d6c69a3c8c179c038d3c1c434735741bb3c8995c3c9d6f2ac'
+MALVARMO_PRIVATE_VIEW_KEY='e514321d6163c9c222f22eb9f43dd1421aee455bb87adb9e0aee138aa8b4b806'
+MALVARMO_PUBLIC_VIEW_KEY='c3fb70733f47f076a70766bfc3ff074e7b7c2663e65394790cc214549458d28e'
+MALVARMO_VANITY_PATTERN='^4.(B'\''V|"x")'
+MALVARMO_VANITY_MATCH='46BV'
+MALVARMO_ATTEMPTS='1337'
+MALVARMO_GENERATED_AT='2020-01-01T00:00:00Z'
+`},
+	} {
+		var out bytes.Buffer
+		if err := printWallet(&out, testWallet(), tc.format, false); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tc.want {
+			t.Fatalf("%s: got\n%s\nexpected\n%s", tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range formats {
+		if err := checkFormat(format); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := checkFormat("jsn"); err == nil {
+		t.Fatal("expected unknown format to be rejected")
+	}
+	if err := printWallet(&bytes.Buffer{}, testWallet(), "jsn", false); err == nil {
+		t.Fatal("expected unknown format to be rejected")
+	}
+}
+
+func TestPrintSplitResult(t *testing.T) {
+	r := &splitResult{
+		Network:  "mainnet",
+		Address:  testWallet().Address,
+		Offset:   testWallet().PrivateSpendKey,
+		Attempts: 1337,
+	}
+	for _, tc := range []struct {
+		format, want string
+	}{
+		// A custom matcher leaves the vanity pattern empty
+		{"text", `Address:        46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN
+Offset:         4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400
+`},
+		{"json", `{"network":"mainnet","address":"46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN","offset":"4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400","attempts":1337}
+`},
+		{"yaml", `network: "mainnet"
+address: "46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN"
+offset: "4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400"
+attempts: 1337
+`},
+		{"env", `MALVARMO_NETWORK='mainnet'
+MALVARMO_ADDRESS='46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN'
+MALVARMO_OFFSET='4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400'
+MALVARMO_ATTEMPTS='1337'
+`},
+	} {
+		var out bytes.Buffer
+		if err := printSplitResult(&out, r, tc.format, true); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tc.want {
+			t.Fatalf("%s: got\n%s\nexpected\n%s", tc.format, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+var (
+	// calibrationTime is the time spent to measure the
+	// search rate before a search is started
+	calibrationTime = time.Second
+	// progressInterval is the interval in which the
+	// progress is updated if stderr is a terminal
+	progressInterval = time.Second
+	// progressLogInterval is the interval in which the
+	// progress is logged if stderr is not a terminal
+	progressLogInterval = time.Minute
+)
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// formatDuration formats a duration given in seconds, which
+// may exceed the longest time.Duration
+func formatDuration(seconds float64) string {
+	const (
+		day  = 24 * 60 * 60
+		year = 365.25 * day
+	)
+	switch {
+	case math.IsInf(seconds, 1) || math.IsNaN(seconds):
+		return "forever"
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= year:
+		return fmt.Sprintf("%.3g years", seconds/year)
+	case seconds >= day:
+		return fmt.Sprintf("%.1f days", seconds/day)
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// printEstimate prints the difficulty of a search
+// and how long it takes at the given rate
+func printEstimate(w io.Writer, d *address.Difficulty, rate float64) {
+	fmt.Fprintf(w, "Expected attempts: %.0f\n", d.ExpectedAttempts())
+	if rate <= 0 {
+		return
+	}
+	fmt.Fprintf(w, "Search rate:       %.0f keys/s\n", rate)
+	fmt.Fprintf(w, "50%% chance after:  %s\n", formatDuration(d.Attempts(0.5)/rate))
+	fmt.Fprintf(w, "90%% chance after:  %s\n", formatDuration(d.Attempts(0.9)/rate))
+}
+
+// progress reports the progress of a running search
+type progress struct {
+	w          io.Writer
+	terminal   bool
+	difficulty *address.Difficulty
+	attempts   *uint64
+	// initial is the number of attempts when the progress started,
+	// which is non-zero for resumed searches
+	initial uint64
+	start   time.Time
+	// shown is set while a progress line is shown on a terminal
+	shown bool
+}
+
+// newProgress returns a reporter for the progress of a search
+// which counts its attempts in attempts. d may be nil if the
+// difficulty of the search is unknown.
+func newProgress(f *os.File, d *address.Difficulty, attempts *uint64) *progress {
+	return &progress{
+		w:          f,
+		terminal:   isTerminal(f),
+		difficulty: d,
+		attempts:   attempts,
+		initial:    atomic.LoadUint64(attempts),
+		start:      time.Now(),
+	}
+}
+
+// interval returns the interval in which the progress is reported
+func (p *progress) interval() time.Duration {
+	if p.terminal {
+		return progressInterval
+	}
+	return progressLogInterval
+}
+
+// line returns the current progress
+func (p *progress) line() string {
+	attempts := atomic.LoadUint64(p.attempts)
+	elapsed := time.Since(p.start).Seconds()
+	rate := float64(attempts-p.initial) / elapsed
+	s := fmt.Sprintf("%d attempts, %.0f keys/s", attempts, rate)
+	if d := p.difficulty; d != nil {
+		s += fmt.Sprintf(", %.1f%% chance so far", 100*d.Found(attempts))
+		for _, q := range []float64{0.5, 0.9} {
+			if remaining := d.Attempts(q) - float64(attempts); remaining > 0 {
+				s += fmt.Sprintf(", %.0f%% chance in %s", 100*q, formatDuration(remaining/rate))
+				break
+			}
+		}
+	}
+	return s
+}
+
+// update reports the current progress
+func (p *progress) update() {
+	if !p.terminal {
+		fmt.Fprintln(p.w, p.line())
+		return
+	}
+	// Overwrite the previous line
+	fmt.Fprintf(p.w, "\r%s\033[K", p.line())
+	p.shown = true
+}
+
+// clear removes the progress line from a terminal
+func (p *progress) clear() {
+	if p.shown {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.shown = false
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leonklingele/malvarmo/address"
+	"github.com/leonklingele/malvarmo/mnemonic"
+)
+
+// readWords reads whitespace-separated words from stdin
+func readWords() ([]string, error) {
+	fmt.Fprintln(os.Stderr, "Enter mnemonic seed:")
+	var words []string
+	sc := bufio.NewScanner(os.Stdin)
+	for len(words) < mnemonic.NumWords && sc.Scan() {
+		words = append(words, strings.Fields(sc.Text())...)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mnemonic seed: %s", err.Error())
+	}
+	return words, nil
+}
+
+func restore(args []string) error {
+	fs := flagSet("restore", "restore [flags] [word ...]\n       "+os.Args[0]+" restore -spend-key <hex> [flags]")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network to restore the address for (mainnet, testnet or stagenet)")
+	language := fs.String("language", "", "optional, the language of the mnemonic seed, detected if omitted ("+languageNames()+")")
+	spendKey := fs.String("spend-key", "", "optional, the hex-encoded private spend key to restore from instead of a mnemonic seed")
+	format := formatFlag(fs)
+	walletFile := walletFileFlags(fs)
+	parseFlags(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	net, err := address.NetworkByName(*network)
+	if err != nil {
+		return err
+	}
+
+	var (
+		spendKeyPair, viewKeyPair *address.KeyPair
+		lang                      *mnemonic.Language
+	)
+	if *spendKey != "" {
+		priv, err := decodeKey("spend-key", *spendKey)
+		if err != nil {
+			return err
+		}
+		if spendKeyPair, viewKeyPair, err = address.FromPrivateSpendKey(priv); err != nil {
+			return fmt.Errorf("failed to restore from private spend key: %s", err.Error())
+		}
+		if lang, err = seedLanguage(*language, nil); err != nil {
+			return err
+		}
+	} else {
+		words, err := seedWords(fs.Args())
+		if err != nil {
+			return err
+		}
+		if lang, err = seedLanguage(*language, words); err != nil {
+			return err
+		}
+		if spendKeyPair, viewKeyPair, err = mnemonic.Decode(words, lang); err != nil {
+			return fmt.Errorf("failed to restore from mnemonic seed: %s", err.Error())
+		}
+	}
+	addr := &address.Address{
+		Network:  net,
+		Type:     address.Standard,
+		SpendKey: spendKeyPair.PublicKey(),
+		ViewKey:  viewKeyPair.PublicKey(),
+	}
+
+	return printResult(net, &address.Result{
+		SpendKeyPair: spendKeyPair,
+		ViewKeyPair:  viewKeyPair,
+		Address:      []byte(addr.String()),
+	}, lang, *format, false, walletFile)
+}
+
+func convert(args []string) error {
+	fs := flagSet("convert", "convert -language <language> [word ...]")
+	language := fs.String("language", "", "the language to convert the mnemonic seed to ("+languageNames()+")")
+	parseFlags(fs, args)
+
+	to, err := mnemonic.LanguageByName(*language)
+	if err != nil {
+		return err
+	}
+	words, err := seedWords(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	seed, err := mnemonic.Convert(words, to)
+	if err != nil {
+		return fmt.Errorf("failed to convert mnemonic seed: %s", err.Error())
+	}
+	fmt.Println("Mnemonic Seed:", strings.Join(seed, " "))
+
+	return nil
+}
+
+// seedWords returns the words of a seed passed as arguments,
+// or reads them from stdin if there are none. Prefer the latter
+// so the seed does not end up in the shell history.
+func seedWords(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	return readWords()
+}
+
+// seedLanguage returns the language with the given name or detects
+// the language of the seed if name is empty. Without a seed to detect
+// the language of, it defaults to English.
+func seedLanguage(name string, words []string) (*mnemonic.Language, error) {
+	if name != "" {
+		return mnemonic.LanguageByName(name)
+	}
+	if words == nil {
+		return mnemonic.English, nil
+	}
+	lang, err := mnemonic.Detect(words)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect language of mnemonic seed: %s", err.Error())
+	}
+	return lang, nil
+}
+
+// languageNames returns a list of all available seed languages
+func languageNames() string {
+	var names []string
+	for _, l := range mnemonic.Languages() {
+		names = append(names, l.EnglishName)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+// maxExpectedAttempts is the largest number of expected attempts
+// of a search which is started without -force. It takes decades
+// on a typical machine.
+const maxExpectedAttempts = 1e15
+
+// searchFlags configure a vanity search
+type searchFlags struct {
+	prefix, regex, patterns *string
+	suffix, contains        *string
+	ignoreCase, eachPattern *bool
+	count, numWorkers       *int
+	quiet, force            *bool
+	// patternList, if set, holds the patterns
+	// instead of the -patterns file
+	patternList [][]byte
+}
+
+// registerSearchFlags registers the flags to configure a vanity search
+func registerSearchFlags(fs *flag.FlagSet) *searchFlags {
+	return &searchFlags{
+		prefix:      fs.String("prefix", "", "optional, the address prefix to search for"),
+		regex:       fs.String("regex", "", "optional, the regular expression to match the full address against, e.g. ^4[0-9AB](Team|TEAM)"),
+		patterns:    fs.String("patterns", "", "optional, the file with address prefixes to search for, one per line"),
+		suffix:      fs.String("suffix", "", "optional, the address suffix to search for"),
+		contains:    fs.String("contains", "", "optional, the word to search for anywhere in the address"),
+		ignoreCase:  fs.Bool("ignore-case", false, "optional, match the prefix, patterns, suffix, word or regular expression in any casing"),
+		count:       fs.Int("count", 1, "optional, the number of addresses to search for"),
+		eachPattern: fs.Bool("each-pattern", false, "optional, search for -count addresses for each of the patterns"),
+		numWorkers:  fs.Int("workers", runtime.GOMAXPROCS(-1), "optional, the number of workers to use for prefix search"),
+		quiet:       fs.Bool("quiet", false, "optional, do not show the difficulty and the progress of the search on stderr"),
+		force:       fs.Bool("force", false, fmt.Sprintf("optional, search even if more than %.0g attempts are expected", maxExpectedAttempts)),
+	}
+}
+
+// multiple reports whether the search may find several addresses
+func (f *searchFlags) multiple() bool {
+	return *f.count > 1 || *f.eachPattern
+}
+
+// options returns the options of the search, or nil if there is
+// nothing to search for
+func (f *searchFlags) options(net address.Network) (*address.SearchOptions, error) {
+	opts := &address.SearchOptions{
+		Network:     net,
+		Prefix:      []byte(*f.prefix),
+		Suffix:      []byte(*f.suffix),
+		Contains:    []byte(*f.contains),
+		IgnoreCase:  *f.ignoreCase,
+		Count:       *f.count,
+		EachPattern: *f.eachPattern,
+		Workers:     *f.numWorkers,
+	}
+	var numModes int
+	for _, s := range []string{*f.prefix, *f.regex, *f.patterns} {
+		if s != "" {
+			numModes++
+		}
+	}
+	switch {
+	case *f.count < 1:
+		return nil, fmt.Errorf("invalid count %d", *f.count)
+	case *f.eachPattern && *f.patterns == "":
+		return nil, fmt.Errorf("-each-pattern requires -patterns")
+	case numModes == 0 && *f.suffix == "" && *f.contains == "":
+		return nil, nil
+	case numModes > 1:
+		return nil, fmt.Errorf("-prefix, -regex and -patterns are mutually exclusive")
+	}
+
+	if *f.regex != "" {
+		var err error
+		if opts.Regex, err = address.NewRegexMatcher(*f.regex, *f.ignoreCase); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %s", err.Error())
+		}
+	}
+	if f.patternList != nil {
+		opts.Patterns = f.patternList
+	} else if *f.patterns != "" {
+		var err error
+		if opts.Patterns, err = readPatterns(*f.patterns); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// readPatterns reads the patterns of a file. Patterns are separated
+// by newlines. Empty lines and lines starting with '#' are ignored.
+func readPatterns(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open patterns file: %s", err.Error())
+	}
+	defer f.Close()
+
+	var patterns [][]byte
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, []byte(line))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read patterns file: %s", err.Error())
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no patterns in %s", path)
+	}
+	return patterns, nil
+}
+
+// search runs a vanity search and calls fn for every address found.
+// The difficulty and the progress of the search are shown on stderr
+// unless -quiet is set. The state of the search is saved to ck, if
+// set, periodically, whenever an address was found and when the
+// search is interrupted.
+func (f *searchFlags) search(opts *address.SearchOptions, ck *checkpointer, fn func(*address.Result) error) error {
+	quiet := *f.quiet
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The difficulty is nil if it is unknown
+	difficulty, _ := opts.Difficulty()
+	if difficulty != nil && difficulty.ExpectedAttempts() > maxExpectedAttempts && !*f.force {
+		return fmt.Errorf("expected %.3g attempts to find an address, use -force to search anyway", difficulty.ExpectedAttempts())
+	}
+	if !quiet && difficulty != nil {
+		rate, err := address.MeasureRate(ctx, *opts, calibrationTime)
+		if err != nil {
+			return fmt.Errorf("failed to measure search rate: %s", err.Error())
+		}
+		printEstimate(os.Stderr, difficulty, rate)
+	}
+
+	var (
+		checkpoints <-chan time.Time
+		interrupts  = make(chan os.Signal, 1)
+	)
+	if ck != nil {
+		ticker := time.NewTicker(ck.interval)
+		defer ticker.Stop()
+		checkpoints = ticker.C
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(interrupts)
+	}
+
+	var attempts uint64
+	opts.Attempts = &attempts
+	results, err := address.Search(ctx, *opts)
+	if err != nil {
+		return fmt.Errorf("failed to create new address: %s", err.Error())
+	}
+	// stop stops the search and saves its final state
+	stop := func(err error) error {
+		cancel()
+		// Wait for all workers to exit
+		for range results {
+		}
+		if ck != nil {
+			if cerr := ck.save(opts.State); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+	if ck != nil {
+		if err := ck.save(opts.State); err != nil {
+			return stop(err)
+		}
+	}
+
+	prog := newProgress(os.Stderr, difficulty, &attempts)
+	ticker := time.NewTicker(prog.interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !quiet {
+				prog.update()
+			}
+		case <-checkpoints:
+			if err := ck.save(opts.State); err != nil {
+				return stop(err)
+			}
+		case <-interrupts:
+			prog.clear()
+			return stop(fmt.Errorf("interrupted, continue the search with -resume %s", ck.path))
+		case res, ok := <-results:
+			if !ok {
+				return stop(nil)
+			}
+			prog.clear()
+			if err := fn(&res); err != nil {
+				return stop(err)
+			}
+			if ck != nil {
+				ck.cp.Found++
+				opts.State.Advance(&res)
+				if err := ck.save(opts.State); err != nil {
+					return stop(err)
+				}
+			}
+		}
+	}
+}
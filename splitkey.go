@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/leonklingele/malvarmo/address"
+	"github.com/leonklingele/malvarmo/mnemonic"
+)
+
+// splitSecret is the file format of the secret half of a split key.
+// It never leaves the machine it was created on.
+type splitSecret struct {
+	PrivateSpendKey string `json:"private_spend_key"`
+	PrivateViewKey  string `json:"private_view_key"`
+	PublicSpendKey  string `json:"public_spend_key"`
+	PublicViewKey   string `json:"public_view_key"`
+}
+
+// splitResult is an address found by a split-key search
+type splitResult struct {
+	Network       string `json:"network"`
+	Address       string `json:"address"`
+	Offset        string `json:"offset"`
+	VanityPattern string `json:"vanity_pattern,omitempty"`
+	VanityMatch   string `json:"vanity_match,omitempty"`
+	Attempts      uint64 `json:"attempts"`
+}
+
+// fields returns the fields of the result in schema order
+func (r *splitResult) fields() []field {
+	return nonEmpty([]field{
+		{"network", r.Network, true},
+		{"address", r.Address, true},
+		{"offset", r.Offset, true},
+		{"vanity_pattern", r.VanityPattern, true},
+		{"vanity_match", r.VanityMatch, true},
+		{"attempts", strconv.FormatUint(r.Attempts, 10), false},
+	})
+}
+
+// printSplitResult prints the result in the given format.
+// lines is set if several results are printed, see printWallet.
+func printSplitResult(out io.Writer, r *splitResult, format string, lines bool) error {
+	switch format {
+	case "text":
+		fmt.Fprintln(out, "Address:       ", r.Address)
+		fmt.Fprintln(out, "Offset:        ", r.Offset)
+		if r.VanityPattern != "" {
+			fmt.Fprintln(out, "Vanity Pattern:", r.VanityPattern)
+			fmt.Fprintln(out, "Vanity Match:  ", r.VanityMatch)
+		}
+	case "json":
+		return printJSON(out, r, lines)
+	default:
+		return printFields(out, r.fields(), format)
+	}
+	return nil
+}
+
+func splitInit(args []string) error {
+	fs := flagSet("split-init", "split-init -secret <file>")
+	secret := fs.String("secret", "", "the file to write the secret keys to, which never leaves this machine")
+	parseFlags(fs, args)
+
+	if *secret == "" {
+		return fmt.Errorf("-secret is required")
+	}
+	k, err := address.NewSplitKey()
+	if err != nil {
+		return fmt.Errorf("failed to create split key: %s", err.Error())
+	}
+	if err := writeOutput(*secret, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(&splitSecret{
+			PrivateSpendKey: hex.EncodeToString(k.Spend.PrivateKey()),
+			PrivateViewKey:  hex.EncodeToString(k.View.PrivateKey()),
+			PublicSpendKey:  hex.EncodeToString(k.Spend.PublicKey()),
+			PublicViewKey:   hex.EncodeToString(k.View.PublicKey()),
+		}); err != nil {
+			return fmt.Errorf("failed to write secret file: %s", err.Error())
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	/*
+		Example output:
+
+		Public Spend Key: 99d0413beb0d7efb7405bbdf45fa30bcda26db6dc1ea8e17ac3b4ac8d83b6b46
+		Public View Key:  f4d178274435565917d1f34caed95ae3eb5daef4e501b1512fbf961a7a75311a
+	*/
+	fmt.Println("Public Spend Key:", hex.EncodeToString(k.Spend.PublicKey()))
+	fmt.Println("Public View Key: ", hex.EncodeToString(k.View.PublicKey()))
+	return nil
+}
+
+func splitSearch(args []string) error {
+	fs := flagSet("split-search", "split-search -spend-key <hex> -view-key <hex> [flags]")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network to search an address for (mainnet, testnet or stagenet)")
+	spendKey := fs.String("spend-key", "", "the public spend key printed by split-init")
+	viewKey := fs.String("view-key", "", "the public view key printed by split-init")
+	searchFlags := registerSearchFlags(fs)
+	format := formatFlag(fs)
+	parseFlags(fs, args)
+
+	net, err := address.NetworkByName(*network)
+	if err != nil {
+		return err
+	}
+	spendPub, err := decodeKey("spend-key", *spendKey)
+	if err != nil {
+		return err
+	}
+	viewPub, err := decodeKey("view-key", *viewKey)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	opts, err := searchFlags.options(net)
+	if err != nil {
+		return err
+	}
+	multiple := searchFlags.multiple()
+	if multiple && *format == "env" {
+		return fmt.Errorf("-format env can only be used for a single address")
+	}
+	if opts == nil {
+		return fmt.Errorf("a pattern to search for is required")
+	}
+	opts.SplitSpendKey, opts.SplitViewKey = spendPub, viewPub
+
+	var n int
+	return searchFlags.search(opts, nil, func(res *address.Result) error {
+		r := &splitResult{
+			Network:       net.Name,
+			Address:       string(res.Address),
+			Offset:        hex.EncodeToString(res.SpendKeyPair.PrivateKey()),
+			VanityPattern: string(res.Pattern),
+			VanityMatch:   string(res.Match),
+			Attempts:      res.Attempts,
+		}
+		if n++; n > 1 {
+			printSeparator(os.Stdout, *format)
+		}
+		return printSplitResult(os.Stdout, r, *format, multiple)
+	})
+}
+
+// readSplitSecret reads the split key written by split-init
+func readSplitSecret(path string) (*address.SplitKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file: %s", err.Error())
+	}
+	var s splitSecret
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse secret file: %s", err.Error())
+	}
+	spend, err := hex.DecodeString(s.PrivateSpendKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private spend key in secret file: %s", err.Error())
+	}
+	view, err := hex.DecodeString(s.PrivateViewKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private view key in secret file: %s", err.Error())
+	}
+	k, err := address.SplitKeyFromPrivateKeys(spend, view)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret file: %s", err.Error())
+	}
+	return k, nil
+}
+
+func splitCombine(args []string) error {
+	fs := flagSet("split-combine", "split-combine -secret <file> -offset <hex> [flags]")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network of the address if no -address is given (mainnet, testnet or stagenet)")
+	secret := fs.String("secret", "", "the secret file written by split-init")
+	offset := fs.String("offset", "", "the offset printed by split-search")
+	addr := fs.String("address", "", "optional, the address printed by split-search, to check the combined keys against")
+	format := formatFlag(fs)
+	walletFile := walletFileFlags(fs)
+	parseFlags(fs, args)
+
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	net, err := address.NetworkByName(*network)
+	if err != nil {
+		return err
+	}
+	var expected *address.Address
+	if *addr != "" {
+		if expected, err = address.Parse(*addr); err != nil {
+			return fmt.Errorf("invalid address: %s", err.Error())
+		}
+		net = expected.Network
+	}
+	k, err := readSplitSecret(*secret)
+	if err != nil {
+		return err
+	}
+	b, err := decodeKey("offset", *offset)
+	if err != nil {
+		return err
+	}
+	spendKeyPair, viewKeyPair, err := k.Combine(b)
+	if err != nil {
+		return fmt.Errorf("failed to combine split key: %s", err.Error())
+	}
+
+	combined := &address.Address{
+		Network:  net,
+		Type:     address.Standard,
+		SpendKey: spendKeyPair.PublicKey(),
+		ViewKey:  viewKeyPair.PublicKey(),
+	}
+	if expected != nil && combined.String() != expected.String() {
+		return fmt.Errorf("the offset and the secret file do not make up address %s", expected)
+	}
+	fmt.Fprintln(os.Stderr, "The view key is not derived from the spend key, so the wallet can not be restored from a mnemonic seed. Back up both private keys.")
+	return printResult(net, &address.Result{
+		SpendKeyPair: spendKeyPair,
+		ViewKeyPair:  viewKeyPair,
+		Address:      []byte(combined.String()),
+	}, mnemonic.English, *format, false, walletFile)
+}
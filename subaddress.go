@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+// decodeKey decodes a hex-encoded key passed via the flag with the given name
+func decodeKey(name, key string) ([]byte, error) {
+	dec, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode -%s: %s", name, err.Error())
+	}
+	if len(dec) != 32 {
+		return nil, fmt.Errorf("invalid length of -%s: %d bytes, expected 32", name, len(dec))
+	}
+	return dec, nil
+}
+
+func subaddress(args []string) error {
+	fs := flagSet("subaddress", "subaddress -view-key <hex> (-address <address> | -public-spend-key <hex> | -spend-key <hex>) [flags]")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network of the wallet if no -address is given (mainnet, testnet or stagenet)")
+	viewKey := fs.String("view-key", "", "the private view key of the wallet")
+	addr := fs.String("address", "", "the primary address of the wallet")
+	publicSpendKey := fs.String("public-spend-key", "", "the public spend key of the wallet")
+	spendKey := fs.String("spend-key", "", "optional, the private spend key of the wallet to also print the private spend keys of the subaddresses")
+	account := fs.Uint("account", 0, "optional, the account (major index) of the subaddresses")
+	from := fs.Uint("from", 0, "optional, the first (minor) index of the subaddresses")
+	count := fs.Uint("count", 10, "optional, the number of subaddresses to print")
+	parseFlags(fs, args)
+
+	viewPriv, err := decodeKey("view-key", *viewKey)
+	if err != nil {
+		return err
+	}
+	var spendPriv address.PrivateKey
+	if *spendKey != "" {
+		if spendPriv, err = decodeKey("spend-key", *spendKey); err != nil {
+			return err
+		}
+	}
+
+	var (
+		net      address.Network
+		spendPub address.PublicKey
+	)
+	switch {
+	case *addr != "":
+		parsed, err := address.Parse(*addr)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", err.Error())
+		}
+		if parsed.Type != address.Standard {
+			return fmt.Errorf("expected a primary address, got a %s address", parsed.Type)
+		}
+		// Subaddresses of a wrong view key belong to nobody
+		if _, err := address.NewWatchOnly(viewPriv, parsed); err != nil {
+			return err
+		}
+		net, spendPub = parsed.Network, parsed.SpendKey
+	case *publicSpendKey != "":
+		if spendPub, err = decodeKey("public-spend-key", *publicSpendKey); err != nil {
+			return err
+		}
+	case spendPriv == nil:
+		return fmt.Errorf("either -address, -public-spend-key or -spend-key is required")
+	}
+	if spendPriv != nil {
+		spendKeyPair, _, err := address.FromPrivateSpendKey(spendPriv)
+		if err != nil {
+			return err
+		}
+		if spendPub != nil && !bytes.Equal(spendPub, spendKeyPair.PublicKey()) {
+			return fmt.Errorf("private spend key does not match the public spend key of the wallet")
+		}
+		spendPub = spendKeyPair.PublicKey()
+	}
+	if *addr == "" {
+		if net, err = address.NetworkByName(*network); err != nil {
+			return err
+		}
+	}
+
+	// Subaddress indices are 32 bits wide
+	const maxIndex = 1 << 32
+	if uint64(*account) >= maxIndex {
+		return fmt.Errorf("-account %d exceeds the largest account %d", *account, uint64(maxIndex-1))
+	}
+	if uint64(*from)+uint64(*count) > maxIndex {
+		return fmt.Errorf("-from %d and -count %d exceed the largest subaddress index %d", *from, *count, uint64(maxIndex-1))
+	}
+
+	/*
+		Example output:
+
+		0/0 4B1ahC2k4bcLxKfnBpViWWRd6a1VjeFdqRLFbEHhoFciW4FYNUAT45D1jNGq4YKejHGBFSE2ktZRqfBFu3tHaLGT5Aug3jk
+		0/1 8...
+	*/
+	for n := uint64(*from); n < uint64(*from)+uint64(*count); n++ {
+		i := uint32(n)
+		sub, err := address.NewSubaddress(net, viewPriv, spendPub, uint32(*account), i)
+		if err != nil {
+			return fmt.Errorf("failed to derive subaddress %d/%d: %s", *account, i, err.Error())
+		}
+		if spendPriv == nil {
+			fmt.Printf("%d/%d %s\n", *account, i, sub)
+			continue
+		}
+		priv, err := address.DeriveSubaddressSpendKey(viewPriv, spendPriv, uint32(*account), i)
+		if err != nil {
+			return fmt.Errorf("failed to derive private spend key of subaddress %d/%d: %s", *account, i, err.Error())
+		}
+		fmt.Printf("%d/%d %s %s\n", *account, i, sub, hex.EncodeToString(priv))
+	}
+
+	return nil
+}
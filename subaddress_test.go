@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubaddressViewKeyMismatch(t *testing.T) {
+	const addr = "46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN"
+	for _, tc := range []struct {
+		viewKey, err string
+	}{
+		{"0100000000000000000000000000000000000000000000000000000000000000", "does not match"},
+		{"e514321d6163c9c222f22eb9f43dd1421aee455bb87adb9e0aee138aa8b4b806", ""},
+	} {
+		err := subaddress([]string{"-view-key", tc.viewKey, "-address", addr, "-count", "1"})
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Fatalf("%s: got error %v, expected %q", tc.viewKey, err, tc.err)
+		}
+	}
+}
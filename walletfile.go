@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// walletFile is the file format monero-wallet-cli restores a wallet from
+// when invoked with --generate-from-json. Watch-only wallets omit the
+// private spend key. Wallets restored from their mnemonic seed omit
+// both private keys.
+type walletFile struct {
+	Version        int    `json:"version"`
+	Filename       string `json:"filename"`
+	ScanFromHeight uint64 `json:"scan_from_height"`
+	Password       string `json:"password"`
+	ViewKey        string `json:"viewkey,omitempty"`
+	SpendKey       string `json:"spendkey,omitempty"`
+	Seed           string `json:"seed,omitempty"`
+	Address        string `json:"address"`
+}
+
+// walletFileOptions configure the wallet file written by a command
+type walletFileOptions struct {
+	path, filename, password *string
+	scanFromHeight           *uint64
+	seed                     *bool
+}
+
+// walletFileFlags registers the flags to configure a wallet file
+func walletFileFlags(fs *flag.FlagSet) *walletFileOptions {
+	return &walletFileOptions{
+		path:           fs.String("wallet-json", "", "optional, the file to write a monero-wallet-cli --generate-from-json file to"),
+		filename:       fs.String("wallet-filename", "malvarmo", "optional, the filename monero-wallet-cli stores the restored wallet as"),
+		password:       fs.String("wallet-password", "", "optional, the password of the restored wallet"),
+		scanFromHeight: fs.Uint64("scan-from-height", 0, "optional, the block height to start scanning the restored wallet from"),
+		seed:           fs.Bool("wallet-json-seed", false, "optional, restore the wallet from its mnemonic seed instead of its private keys"),
+	}
+}
+
+// write writes the wallet file if requested. Without a private spend
+// key, a watch-only wallet file is written. seed is the mnemonic seed
+// of the wallet, or empty if it has none.
+func (o *walletFileOptions) write(spendPriv, viewPriv []byte, seed, addr string) error {
+	if *o.path == "" {
+		return nil
+	}
+	wf := &walletFile{
+		Version:        1,
+		Filename:       *o.filename,
+		ScanFromHeight: *o.scanFromHeight,
+		Password:       *o.password,
+		Address:        addr,
+	}
+	switch {
+	case *o.seed && seed == "":
+		return fmt.Errorf("-wallet-json-seed requires a wallet with a mnemonic seed")
+	case *o.seed:
+		wf.Seed = seed
+	default:
+		wf.ViewKey = hex.EncodeToString(viewPriv)
+		if spendPriv != nil {
+			wf.SpendKey = hex.EncodeToString(spendPriv)
+		}
+	}
+	return writeOutput(*o.path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(wf); err != nil {
+			return fmt.Errorf("failed to write wallet file: %s", err.Error())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// decodeHex decodes a hex-encoded key, or returns nil if it is empty
+func decodeHex(t *testing.T, s string) []byte {
+	if s == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWalletFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	const (
+		spendPriv = "4a078e76cd41a3d3b534b83dc6f2ea2de500b653ca82273b7bfad8045d85a400"
+		viewPriv  = "e514321d6163c9c222f22eb9f43dd1421aee455bb87adb9e0aee138aa8b4b806"
+		seed      = "the seed"
+		addr      = "46BVM4CnrP53FE2gcT3LJjAWJ6fGWq8t8YKRqwwit8vmVu3TJhqmYeKLr5VaNKENaJE8Nt1kdzpeFMFLS6aaePC5H35CgTN"
+	)
+	for i, tc := range []struct {
+		args      []string
+		spendPriv string
+		// fields are the expected fields besides
+		// the ones which are always present
+		fields map[string]interface{}
+	}{
+		{nil, spendPriv, map[string]interface{}{"spendkey": spendPriv, "viewkey": viewPriv}},
+		{nil, "", map[string]interface{}{"viewkey": viewPriv}},
+		{[]string{"-wallet-json-seed"}, spendPriv, map[string]interface{}{"seed": seed}},
+	} {
+		path := filepath.Join(dir, string(rune('a'+i)))
+		fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+		o := walletFileFlags(fs)
+		if err := fs.Parse(append([]string{"-wallet-json", path, "-scan-from-height", "3000000"}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.write(decodeHex(t, tc.spendPriv), decodeHex(t, viewPriv), seed, addr); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{
+			"version":          1.0,
+			"filename":         "malvarmo",
+			"scan_from_height": 3000000.0,
+			"password":         "",
+			"address":          addr,
+		}
+		for k, v := range tc.fields {
+			want[k] = v
+		}
+		if !reflect.DeepEqual(got, want) {
+			var keys []string
+			for k := range got {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			t.Fatalf("%v: got wallet file with fields %v: %s", tc.args, keys, data)
+		}
+	}
+
+	// A wallet without a seed can not be restored from it
+	fs := flag.NewFlagSet("split-combine", flag.ContinueOnError)
+	o := walletFileFlags(fs)
+	if err := fs.Parse([]string{"-wallet-json", filepath.Join(dir, "split"), "-wallet-json-seed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.write(decodeHex(t, spendPriv), decodeHex(t, viewPriv), "", addr); err == nil {
+		t.Fatal("expected wallet without a seed to be rejected")
+	}
+}
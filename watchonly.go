@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leonklingele/malvarmo/address"
+)
+
+// writeOutput writes to the file at path, or to stdout if path is empty.
+// Files are only readable by the current user as they may contain secrets.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %s", err.Error())
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %s", err.Error())
+	}
+	return nil
+}
+
+func watchOnly(args []string) error {
+	fs := flagSet("watch-only", "watch-only -view-key <hex> (-address <address> | -public-spend-key <hex>) [flags]")
+	network := fs.String("network", address.Mainnet.Name, "optional, the network of the wallet if no -address is given (mainnet, testnet or stagenet)")
+	viewKey := fs.String("view-key", "", "the private view key of the wallet")
+	addr := fs.String("address", "", "the primary address of the wallet")
+	publicSpendKey := fs.String("public-spend-key", "", "the public spend key of the wallet")
+	out := fs.String("out", "", "optional, the file to write the watch-only bundle to instead of stdout")
+	walletFile := walletFileFlags(fs)
+	parseFlags(fs, args)
+
+	viewPriv, err := decodeKey("view-key", *viewKey)
+	if err != nil {
+		return err
+	}
+
+	var wo *address.WatchOnly
+	switch {
+	case *addr != "":
+		parsed, err := address.Parse(*addr)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", err.Error())
+		}
+		if wo, err = address.NewWatchOnly(viewPriv, parsed); err != nil {
+			return fmt.Errorf("failed to create watch-only bundle: %s", err.Error())
+		}
+	case *publicSpendKey != "":
+		net, err := address.NetworkByName(*network)
+		if err != nil {
+			return err
+		}
+		spendPub, err := decodeKey("public-spend-key", *publicSpendKey)
+		if err != nil {
+			return err
+		}
+		if wo, err = address.NewWatchOnlyFromSpendKey(net, viewPriv, spendPub); err != nil {
+			return fmt.Errorf("failed to create watch-only bundle: %s", err.Error())
+		}
+	default:
+		return fmt.Errorf("either -address or -public-spend-key is required")
+	}
+
+	if err := walletFile.write(nil, wo.ViewKey, "", wo.Address.String()); err != nil {
+		return err
+	}
+	return writeOutput(*out, func(w io.Writer) error {
+		/*
+			Example output:
+
+			Public Spend Key: f7b84112e3d36b774bcf01e63218439335171562c0d1b8917897b656cfe9ffad
+			Private View Key: 699443b7ba8a0744b54b5a99b0197f0471c2d19027307fac3315d3b67ede640b
+			Address:          4B1ahC2k4bcLxKfnBpViWWRd6a1VjeFdqRLFbEHhoFciW4FYNUAT45D1jNGq4YKejHGBFSE2ktZRqfBFu3tHaLGT5Aug3jk
+		*/
+		_, err := fmt.Fprintf(w, "Public Spend Key: %s\nPrivate View Key: %s\nAddress:          %s\n",
+			hex.EncodeToString(wo.SpendKey),
+			hex.EncodeToString(wo.ViewKey),
+			wo.Address,
+		)
+		return err
+	})
+}